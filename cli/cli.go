@@ -3,6 +3,7 @@ package cli
 
 import (
     "os"
+    "slices"
 )
 
 // Structure containing the parsed result of the given command line arguments.
@@ -15,7 +16,8 @@ type CLI struct {
     Subcommand string
     // Map containing each included flag and its associated value. Both single
     // and double dash flags are supported (e.g. -v vs. --verbose). Multiple
-    // characters following a single dash will each appear in the map.
+    // characters following a single dash will each appear in the map without
+    // a value, a single character takes a value as a double dash flag does.
     //
     // A flag can be present without containing a value, this is represented
     // with an empty string. When using a flag value do not only check the
@@ -35,7 +37,12 @@ type CLI struct {
 // each parsed element. There is no schema logic and error handling for invalid
 // flags, commands and arguments should be handled after the parse by the code
 // that called it.
-func Parse() *CLI {
+//
+// A long flag or a single short flag takes the word following it as its
+// value (e.g. -d yesterday), except for the given boolean flags which never
+// have a value, e.g. with "json" the word after --json is parsed as an
+// argument.
+func Parse(boolFlags ...string) *CLI {
     var command string
     var subcommand string
     var flags = make(map[string]string)
@@ -58,7 +65,12 @@ func Parse() *CLI {
             continue
         }
 
-        if a[0] == '-' && len(a) > 1 {
+        if flagAwaitingValue != "" && a[0] == '-' && len(a) > 1 && a[1] >= '0' && a[1] <= '9' {
+            // a dash followed by a digit is a negative value (e.g. --date
+            // -2h) rather than a flag when a flag is awaiting its value
+            flags[flagAwaitingValue] = a
+            flagAwaitingValue = ""
+        } else if a[0] == '-' && len(a) > 1 {
             // if word begins with a dash, most likely a flag
 
             // one flag following another means that the previous does not
@@ -70,7 +82,11 @@ func Parse() *CLI {
             if a[1] == '-' && len(a) > 2 {
                 // determined most likely a long value (-- double dash)
                 flag := a[2:]
-                if flag[0] != '-' {
+                if flag[0] != '-' && slices.Contains(boolFlags, flag) {
+                    // boolean flag, present without a value
+                    flags[flag] = ""
+                    flagAwaitingValue = ""
+                } else if flag[0] != '-' {
                     // confirm that flag key does not begin with -
                     flagAwaitingValue = flag
                 } else {
@@ -80,8 +96,12 @@ func Parse() *CLI {
             } else if len(a) > 1 {
                 // determined to most likely be a short value (- single dash)
                 flag := a[1:]
-                if flag[0] != '-' {
+                if len(flag) == 1 && flag[0] != '-' && !slices.Contains(boolFlags, flag) {
+                    // a single short flag can take a value
+                    flagAwaitingValue = flag
+                } else if flag[0] != '-' {
                     // confirm that flag key does not begin with -
+                    flagAwaitingValue = ""
                     for _, f := range flag {
                         // loop each letter in the short flag and add to the
                         // flag map with an empty value
@@ -128,3 +148,18 @@ func Parse() *CLI {
     }
 }
 
+
+// Look up the first of the given flag names that is present, e.g. the short
+// and long form of the same flag ("d", "date"). Returns the flag value and
+// true if any of the names are present, an empty string and false if none
+// are.
+//
+// As with Flags, a present flag can still have an empty value.
+func (c *CLI) Flag(names ...string) (string, bool) {
+    for _, name := range names {
+        if v, ok := c.Flags[name]; ok {
+            return v, true
+        }
+    }
+    return "", false
+}
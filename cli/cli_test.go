@@ -75,3 +75,79 @@ func TestParse(t *testing.T) {
     }
 }
 
+
+// Testing the Flag() lookup which returns the first present flag of the
+// provided names, used for short and long forms of the same flag.
+func TestFlag(t *testing.T) {
+    os.Args = []string{"yarrienet", "command1", "-d", "--long", "value", "--empty"}
+    cli := Parse()
+
+    // long form present, short form missing
+    if v, ok := cli.Flag("l", "long"); !ok || v != "value" {
+        t.Errorf("expected flag 'long' to be present with value 'value' not '%s' (present: %t)", v, ok)
+    }
+    // short form present without value, first name wins
+    if v, ok := cli.Flag("d", "date"); !ok || v != "" {
+        t.Errorf("expected flag 'd' to be present with an empty value not '%s' (present: %t)", v, ok)
+    }
+    // present without value
+    if _, ok := cli.Flag("empty"); !ok {
+        t.Errorf("expected flag 'empty' to be present")
+    }
+    // missing entirely
+    if v, ok := cli.Flag("x", "missing"); ok {
+        t.Errorf("expected flags 'x' and 'missing' to be absent not '%s'", v)
+    }
+}
+
+// Testing that a word beginning with a dash and a digit is the value of a flag
+// awaiting one, rather than short flags.
+func TestParseNegativeValue(t *testing.T) {
+    os.Args = []string{"yarrienet", "command1", "--date", "-2h", "-5"}
+    cli := Parse()
+
+    if v, ok := cli.Flags["date"]; !ok || v != "-2h" {
+        t.Errorf("expected flag 'date' to have value '-2h' not '%s' (present: %t)", v, ok)
+    }
+    // no flag awaiting a value, parsed as a short flag
+    if _, ok := cli.Flags["5"]; !ok {
+        t.Errorf("expected short flag '5' to be present")
+    }
+}
+
+// Testing that a boolean flag never takes the following word as its value,
+// leaving it as an argument.
+func TestParseBoolFlags(t *testing.T) {
+    os.Args = []string{"yarrienet", "feed", "diff", "--json", "old.xml", "--awaiting", "--json", "new.xml", "--limit", "5"}
+    cli := Parse("json")
+
+    if v, ok := cli.Flags["json"]; !ok || v != "" {
+        t.Errorf("expected flag 'json' to be present without a value not '%s' (present: %t)", v, ok)
+    }
+    if v, ok := cli.Flags["awaiting"]; !ok || v != "" {
+        t.Errorf("expected flag 'awaiting' followed by a boolean flag to have no value not '%s' (present: %t)", v, ok)
+    }
+    if v := cli.Flags["limit"]; v != "5" {
+        t.Errorf("expected flag 'limit' to have value '5' not '%s'", v)
+    }
+    if len(cli.Arguments) != 2 || cli.Arguments[0] != "old.xml" || cli.Arguments[1] != "new.xml" {
+        t.Errorf("expected arguments [old.xml new.xml] not %v", cli.Arguments)
+    }
+}
+
+// Testing that a single short flag takes a value unless it is boolean, while
+// grouped short flags never do.
+func TestParseShortValue(t *testing.T) {
+    os.Args = []string{"yarrienet", "microblog", "new", "-d", "yesterday 18:30", "-h", "index.html", "-c", "-xy", "--date", "-ab", "arg"}
+    cli := Parse("h")
+
+    expected := map[string]string{"d": "yesterday 18:30", "h": "", "c": "", "x": "", "y": "", "date": "", "a": "", "b": ""}
+    for flag, value := range expected {
+        if v, ok := cli.Flags[flag]; !ok || v != value {
+            t.Errorf("expected flag '%s' to have value '%s' not '%s' (present: %t)", flag, value, v, ok)
+        }
+    }
+    if len(cli.Arguments) != 2 || cli.Arguments[0] != "index.html" || cli.Arguments[1] != "arg" {
+        t.Errorf("expected arguments [index.html arg] not %v", cli.Arguments)
+    }
+}
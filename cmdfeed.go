@@ -0,0 +1,155 @@
+package main
+
+import (
+    "yarrienet/rsshelper"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "time"
+)
+
+// Read and decode a feed of any format from a path, '-' reads stdin.
+func readFeed(path string) (*rsshelper.Feed, error) {
+    var data []byte
+    var err error
+    if path == "-" {
+        data, err = io.ReadAll(os.Stdin)
+    } else {
+        data, err = os.ReadFile(resolvePath(path))
+    }
+    if err != nil {
+        return nil, err
+    }
+    feed, err := rsshelper.Decode(data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+    }
+    return feed, nil
+}
+
+// Print a feed diff as text, one line per item followed by the diff of its
+// content.
+func printFeedDiff(diff *rsshelper.FeedDiff) {
+    var added, removed, changed int
+    for _, d := range diff.Items {
+        title := ""
+        if d.Title != "" {
+            title = fmt.Sprintf(" \"%s\"", d.Title)
+        }
+        switch {
+        case d.Added:
+            added++
+            fmt.Printf("+ %s%s added\n", d.ID, title)
+            continue
+        case d.Removed:
+            removed++
+            fmt.Printf("- %s%s removed\n", d.ID, title)
+            continue
+        }
+        changed++
+        var changes []string
+        if d.Redated() {
+            changes = append(changes, fmt.Sprintf("re-dated %s -> %s", d.OldPublished.Format(time.RFC3339), d.NewPublished.Format(time.RFC3339)))
+        }
+        if len(d.Changed) > 0 {
+            changes = append(changes, "changed " + strings.Join(d.Changed, ", "))
+        }
+        fmt.Printf("~ %s%s %s\n", d.ID, title, strings.Join(changes, ", "))
+        for _, line := range d.ContentDiff {
+            fmt.Printf("    %s %s\n", line.Op, line.Text)
+        }
+    }
+    fmt.Printf("%d added, %d removed, %d changed\n", added, removed, changed)
+}
+
+// Feed diff command. Compares the items of two feeds of any format and
+// prints the items added, removed, re-dated or changed, or a JSON object with
+// --json. Returns a status code like diff(1), 0 when the feeds have the same
+// items, 1 when they differ and 2 on errors.
+func cmdFeedDiff() int {
+    if len(c.Arguments) != 2 {
+        fmt.Fprintf(os.Stderr, "[error] feed diff requires an old and a new feed\n")
+        return 2
+    }
+    old, err := readFeed(c.Arguments[0])
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 2
+    }
+    new, err := readFeed(c.Arguments[1])
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 2
+    }
+
+    diff := rsshelper.DiffFeeds(old, new)
+    if _, jsonFlag := c.Flag("json"); jsonFlag {
+        data, err := json.MarshalIndent(diff, "", "    ")
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to encode json: %s\n", err)
+            return 2
+        }
+        fmt.Println(string(data))
+    } else {
+        printFeedDiff(diff)
+    }
+    if !diff.Empty() {
+        return 1
+    }
+    return 0
+}
+
+// Feed validate command. Checks a feed of any format against the rules of
+// its specification and prints the problems found, or a JSON array with
+// --json. Returns 0 when the feed is valid, warnings aside, 1 when it has
+// errors and 2 when it cannot be read.
+func cmdFeedValidate() int {
+    if len(c.Arguments) != 1 {
+        fmt.Fprintf(os.Stderr, "[error] feed validate requires a feed\n")
+        return 2
+    }
+    path := c.Arguments[0]
+    var data []byte
+    var err error
+    if path == "-" {
+        data, err = io.ReadAll(os.Stdin)
+    } else {
+        data, err = os.ReadFile(resolvePath(path))
+    }
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 2
+    }
+    problems, err := rsshelper.Validate(data)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to validate %s: %s\n", path, err)
+        return 2
+    }
+
+    if _, jsonFlag := c.Flag("json"); jsonFlag {
+        if problems == nil {
+            problems = []rsshelper.Problem{}
+        }
+        data, err := json.MarshalIndent(problems, "", "    ")
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to encode json: %s\n", err)
+            return 2
+        }
+        fmt.Println(string(data))
+    } else {
+        var errorCount int
+        for _, p := range problems {
+            if p.Severity == rsshelper.SeverityError {
+                errorCount++
+            }
+            fmt.Println(p)
+        }
+        fmt.Printf("%d errors, %d warnings\n", errorCount, len(problems) - errorCount)
+    }
+    if rsshelper.HasErrors(problems) {
+        return 1
+    }
+    return 0
+}
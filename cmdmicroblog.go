@@ -0,0 +1,463 @@
+package main
+
+import (
+    "yarrienet/markdown"
+    "yarrienet/microblog"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "os/exec"
+    "regexp"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "time"
+)
+
+// Editor used when $EDITOR is not set.
+const defaultEditor = "vi"
+
+// Determine the microblog HTML path from the argument at index i, falling
+// back on the path defined in the config file. The argument supersedes the
+// config file entry. Returns the resolved path, an empty string when neither
+// are provided.
+func microblogHtmlPath(i int) string {
+    var htmlPath string
+    if conf != nil {
+        htmlPath = conf.MicroblogHtmlFile
+    }
+    if len(c.Arguments) > i {
+        htmlPath = c.Arguments[i]
+    }
+    return resolvePath(htmlPath)
+}
+
+// Open the source in the user's editor ($EDITOR) using a temporary file with
+// the given name pattern, see os.CreateTemp. Blocks until the editor exits.
+// The temporary file is kept once the editor has run so that the edit can be
+// recovered when it is rejected, the caller removes it. Returns the edited
+// source and the path of the temporary file, an error if the editor fails.
+func editInEditor(pattern string, source string) (string, string, error) {
+    tmp, err := os.CreateTemp("", pattern)
+    if err != nil {
+        return "", "", err
+    }
+
+    _, err = tmp.WriteString(source)
+    tmp.Close()
+    if err != nil {
+        os.Remove(tmp.Name())
+        return "", "", err
+    }
+
+    // $EDITOR may contain arguments, e.g. "code --wait"
+    editor := strings.Fields(os.Getenv("EDITOR"))
+    if len(editor) == 0 {
+        editor = []string{defaultEditor}
+    }
+    cmd := exec.Command(editor[0], append(editor[1:], tmp.Name())...)
+    cmd.Stdin = os.Stdin
+    cmd.Stdout = os.Stdout
+    cmd.Stderr = os.Stderr
+    if err = cmd.Run(); err != nil {
+        return "", tmp.Name(), fmt.Errorf("editor failed: %s", err)
+    }
+
+    edited, err := os.ReadFile(tmp.Name())
+    if err != nil {
+        return "", tmp.Name(), err
+    }
+    return string(edited), tmp.Name(), nil
+}
+
+// Microblog edit command. Opens the body of a single post in the user's
+// editor and splices the result back into the microblog HTML page. The page
+// is left untouched if the edited body is empty or invalid. Returns a status
+// code, success is 0.
+func cmdMicroblogEdit() int {
+    if len(c.Arguments) < 1 {
+        fmt.Fprintf(os.Stderr, "[error] missing post id\n")
+        return 1
+    }
+    if len(c.Arguments) > 2 {
+        fmt.Fprintf(os.Stderr, "[error] more than two arguments provided\n")
+        return 1
+    }
+    id := c.Arguments[0]
+
+    htmlPath := microblogHtmlPath(1)
+    if htmlPath == "" {
+        fmt.Fprintf(os.Stderr, "[error] missing html path\n")
+        return 1
+    }
+
+    src, err := os.ReadFile(htmlPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to read html file: %s\n", htmlPath)
+        return 1
+    }
+
+    // the edit is kept in the temporary file until it is written
+    var tmpPath string
+    src, changed, err := microblog.EditPost(src, id, func(body string) (string, error) {
+        edited, path, err := editInEditor("yarrienet-" + id + "-*.html", body)
+        tmpPath = path
+        return edited, err
+    })
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to edit post: %s\n", err)
+        if tmpPath != "" {
+            fmt.Fprintf(os.Stderr, "[error] the edited post is kept in %s\n", tmpPath)
+        }
+        return 1
+    }
+    if !changed {
+        os.Remove(tmpPath)
+        fmt.Fprintf(os.Stderr, "post '%s' unchanged\n", id)
+        return 0
+    }
+    if err = writeFile(htmlPath, src); err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to write html file: %s\n", err)
+        fmt.Fprintf(os.Stderr, "[error] the edited post is kept in %s\n", tmpPath)
+        return 1
+    }
+    os.Remove(tmpPath)
+    return 0
+}
+
+// Microblog delete command. Removes a post from the microblog HTML page,
+// optionally leaving a tombstone in its place with --tombstone. Returns a
+// status code, success is 0.
+func cmdMicroblogDelete() int {
+    if len(c.Arguments) < 1 {
+        fmt.Fprintf(os.Stderr, "[error] missing post id\n")
+        return 1
+    }
+    if len(c.Arguments) > 2 {
+        fmt.Fprintf(os.Stderr, "[error] more than two arguments provided\n")
+        return 1
+    }
+    id := c.Arguments[0]
+    _, tombstone := c.Flag("tombstone")
+
+    htmlPath := microblogHtmlPath(1)
+    if htmlPath == "" {
+        fmt.Fprintf(os.Stderr, "[error] missing html path\n")
+        return 1
+    }
+
+    src, err := os.ReadFile(htmlPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to read html file: %s\n", htmlPath)
+        return 1
+    }
+
+    src, err = microblog.DeletePost(src, id, tombstone, time.Now())
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to delete post: %s\n", err)
+        return 1
+    }
+    if err = writeFile(htmlPath, src); err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to write html file: %s\n", err)
+        return 1
+    }
+    return 0
+}
+
+// Length of the excerpt printed by microblog list.
+const listExcerptLength = 60
+// Width that microblog show wraps paragraphs at.
+const showWidth = 80
+
+// Parse a date provided to a flag in any form accepted by microblog.ParseDate,
+// read in the configured timezone. Returns the parsed time, an error on
+// failure.
+func parseDateFlag(s string) (time.Time, error) {
+    dateFormat, err := microblogDateFormat()
+    if err != nil {
+        return time.Time{}, err
+    }
+    return microblog.ParseDate(s, time.Now(), dateFormat.Location)
+}
+
+// Parse the end of a date range provided to a flag, a date without a time is
+// the end of that day, see microblog.ParseDateEnd. Returns the parsed time,
+// an error on failure.
+func parseDateEndFlag(s string) (time.Time, error) {
+    dateFormat, err := microblogDateFormat()
+    if err != nil {
+        return time.Time{}, err
+    }
+    return microblog.ParseDateEnd(s, time.Now(), dateFormat.Location)
+}
+
+// Read the posts from the microblog file at the path. Returns the posts, an
+// error on failure. A microblog without posts is not an error.
+func readPosts(htmlPath string) ([]microblog.Post, error) {
+    f, err := os.Open(htmlPath)
+    if err != nil {
+        return nil, fmt.Errorf("failed to open microblog file: %w", err)
+    }
+    defer f.Close()
+
+    posts, err := microblog.ParsePostsFile(f)
+    if err != nil {
+        return nil, fmt.Errorf("failed to parse microblog file: %w", err)
+    }
+    return posts, nil
+}
+
+// Post as printed by microblog list --json.
+type listedPost struct {
+    ID string `json:"id"`
+    Date time.Time `json:"date"`
+    Excerpt string `json:"excerpt"`
+}
+
+// Microblog list command. Prints the ID, date and an excerpt of each post
+// matching the filter flags, or a JSON array with --json. Returns a status
+// code, success is 0.
+func cmdMicroblogList() int {
+    if len(c.Arguments) > 1 {
+        fmt.Fprintf(os.Stderr, "[error] more than one argument provided\n")
+        return 1
+    }
+    htmlPath := microblogHtmlPath(0)
+    if htmlPath == "" {
+        fmt.Fprintf(os.Stderr, "[error] missing microblog html file\n")
+        return 1
+    }
+
+    // build the filter from flags, each flag requires a value
+    var filter microblog.Filter
+    var err error
+    if v, ok := c.Flag("since"); ok {
+        if filter.Since, err = parseDateFlag(v); err != nil {
+            fmt.Fprintf(os.Stderr, "[error] since flag: %s\n", err)
+            return 1
+        }
+    }
+    if v, ok := c.Flag("until"); ok {
+        if filter.Until, err = parseDateEndFlag(v); err != nil {
+            fmt.Fprintf(os.Stderr, "[error] until flag: %s\n", err)
+            return 1
+        }
+    }
+    if v, ok := c.Flag("limit"); ok {
+        if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 1 {
+            fmt.Fprintf(os.Stderr, "[error] limit flag expects a positive integer\n")
+            return 1
+        }
+    }
+    if v, ok := c.Flag("grep"); ok {
+        if filter.Grep, err = regexp.Compile(v); err != nil {
+            fmt.Fprintf(os.Stderr, "[error] grep flag: %s\n", err)
+            return 1
+        }
+    }
+    _, jsonFlag := c.Flag("json")
+
+    posts, err := readPosts(htmlPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 1
+    }
+    posts = microblog.FilterPosts(posts, filter)
+
+    if jsonFlag {
+        listed := make([]listedPost, 0, len(posts))
+        for _, post := range posts {
+            listed = append(listed, listedPost{
+                ID: post.ID,
+                Date: post.DatePosted,
+                Excerpt: post.Summary(listExcerptLength),
+            })
+        }
+        data, err := json.MarshalIndent(listed, "", "    ")
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to encode json: %s\n", err)
+            return 1
+        }
+        fmt.Println(string(data))
+        return 0
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    for _, post := range posts {
+        fmt.Fprintf(w, "%s\t%s\t%s\n", post.ID, post.DatePosted.Format("2006-01-02 15:04"), post.Summary(listExcerptLength))
+    }
+    w.Flush()
+    return 0
+}
+
+// Microblog show command. Prints a single post as readable terminal text with
+// wrapped paragraphs and links as footnotes. Returns a status code, success
+// is 0.
+func cmdMicroblogShow() int {
+    if len(c.Arguments) < 1 {
+        fmt.Fprintf(os.Stderr, "[error] missing post id\n")
+        return 1
+    }
+    if len(c.Arguments) > 2 {
+        fmt.Fprintf(os.Stderr, "[error] more than two arguments provided\n")
+        return 1
+    }
+    id := c.Arguments[0]
+    htmlPath := microblogHtmlPath(1)
+    if htmlPath == "" {
+        fmt.Fprintf(os.Stderr, "[error] missing microblog html file\n")
+        return 1
+    }
+
+    posts, err := readPosts(htmlPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 1
+    }
+    post := microblog.PostByID(posts, id)
+    if post == nil {
+        fmt.Fprintf(os.Stderr, "[error] post '%s' not found\n", id)
+        return 1
+    }
+
+    if post.Deleted {
+        if post.DateDeleted.IsZero() {
+            fmt.Printf("%s\ndeleted\n", post.ID)
+        } else {
+            fmt.Printf("%s\ndeleted %s\n", post.ID, post.DateDeleted.Format("2006-01-02 15:04"))
+        }
+        return 0
+    }
+    fmt.Printf("%s\n%s\n\n%s\n", post.ID, post.DatePosted.Format("2006-01-02 15:04"), post.Text(true).Wrap(showWidth))
+    return 0
+}
+
+// Read the body of a new post from the --body, --body-file or stdin sources,
+// only one source may be used. A body file of '-' also reads from stdin. The
+// body is converted from Markdown when --markdown is present. Returns the
+// HTML body, an empty string when no source is provided.
+func readPostBody(stdin bool) (string, error) {
+    bodyFlag, bodyFlagUsed := c.Flag("body")
+    bodyFile, bodyFileUsed := c.Flag("body-file")
+    if bodyFileUsed && bodyFile == "-" {
+        stdin = true
+        bodyFileUsed = false
+    }
+    // the stdin argument following --markdown is parsed as its value
+    markdownFlag, markdownFlagUsed := c.Flag("markdown")
+    if markdownFlag == "-" {
+        stdin = true
+    } else if markdownFlag != "" {
+        return "", fmt.Errorf("markdown flag takes no value, got '%s'", markdownFlag)
+    }
+
+    var sources = 0
+    for _, used := range []bool{bodyFlagUsed, bodyFileUsed, stdin} {
+        if used {
+            sources++
+        }
+    }
+    if sources > 1 {
+        return "", fmt.Errorf("only one of --body, --body-file and stdin can be used")
+    }
+
+    var body string
+    switch {
+    case bodyFlagUsed:
+        body = bodyFlag
+    case bodyFileUsed:
+        if bodyFile == "" {
+            return "", fmt.Errorf("body file flag missing value")
+        }
+        data, err := os.ReadFile(resolvePath(bodyFile))
+        if err != nil {
+            return "", fmt.Errorf("failed to read body file: %s", err)
+        }
+        body = string(data)
+    case stdin:
+        data, err := io.ReadAll(os.Stdin)
+        if err != nil {
+            return "", fmt.Errorf("failed to read body from stdin: %s", err)
+        }
+        body = string(data)
+    }
+
+    if markdownFlagUsed {
+        body = markdown.ToHTML(body)
+    }
+    return body, nil
+}
+
+// Microblog undo command. Restores the newest backup of the files last changed
+// by any command. Returns a status code, success is 0.
+func cmdMicroblogUndo() int {
+    if len(c.Arguments) > 0 {
+        fmt.Fprintf(os.Stderr, "[error] undo takes no arguments\n")
+        return 1
+    }
+    b := backups()
+    if b == nil {
+        fmt.Fprintf(os.Stderr, "[error] backups are disabled\n")
+        return 1
+    }
+    paths, err := b.Undo()
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to undo: %s\n", err)
+        return 1
+    }
+    for _, path := range paths {
+        fmt.Fprintf(os.Stderr, "restored %s\n", path)
+    }
+    return 0
+}
+
+// The display date format configured by microblog_date_layout,
+// microblog_locale and microblog_timezone. Returns an error when the config
+// values are invalid.
+func microblogDateFormat() (*microblog.DateFormat, error) {
+    if conf == nil {
+        return &microblog.DateFormat{}, nil
+    }
+    return microblog.NewDateFormat(conf.MicroblogDateLayout, conf.MicroblogLocale, conf.MicroblogTimezone)
+}
+
+// Microblog fmt command. Rewrites the visible date of every post from its
+// datetime using the configured date format. Returns a status code, success
+// is 0.
+func cmdMicroblogFmt() int {
+    if len(c.Arguments) > 1 {
+        fmt.Fprintf(os.Stderr, "[error] more than one argument provided\n")
+        return 1
+    }
+    htmlPath := microblogHtmlPath(0)
+    if htmlPath == "" {
+        fmt.Fprintf(os.Stderr, "[error] missing html path\n")
+        return 1
+    }
+    dateFormat, err := microblogDateFormat()
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 1
+    }
+
+    src, err := os.ReadFile(htmlPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to read html file: %s\n", htmlPath)
+        return 1
+    }
+    src, changed, err := microblog.FormatDates(src, dateFormat)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to format dates: %s\n", err)
+        return 1
+    }
+    fmt.Fprintf(os.Stderr, "%d posts changed\n", changed)
+    if changed == 0 {
+        return 0
+    }
+    if err = writeFile(htmlPath, src); err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to write html file: %s\n", err)
+        return 1
+    }
+    return 0
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+    "yarrienet/cli"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// Replace stdin with a file of the data for the test.
+func setStdin(t *testing.T, data string) {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "stdin")
+    if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+        t.Fatalf("failed to write stdin: %s", err)
+    }
+    f, err := os.Open(path)
+    if err != nil {
+        t.Fatalf("failed to open stdin: %s", err)
+    }
+    stdin := os.Stdin
+    os.Stdin = f
+    t.Cleanup(func() {
+        os.Stdin = stdin
+        f.Close()
+    })
+}
+
+// Testing that readPostBody reads Markdown from stdin when the '-' argument
+// is parsed as the value of --markdown.
+func TestReadPostBodyMarkdownStdin(t *testing.T) {
+    setStdin(t, "hello *world*\n")
+    c = &cli.CLI{Flags: map[string]string{"markdown": "-"}}
+    t.Cleanup(func() { c = nil })
+
+    body, err := readPostBody(false)
+    if err != nil {
+        t.Fatalf("failed to read body: %s", err)
+    }
+    if strings.TrimSpace(body) != "<p>hello <em>world</em></p>" {
+        t.Errorf("expected the markdown of stdin as html not '%s'", body)
+    }
+
+    // stdin is still one source
+    c.Flags["body"] = "<p>body</p>"
+    if _, err := readPostBody(false); err == nil {
+        t.Errorf("expected --body and '--markdown -' together to fail")
+    }
+
+    c = &cli.CLI{Flags: map[string]string{"markdown": "index.html"}}
+    if _, err := readPostBody(false); err == nil {
+        t.Errorf("expected a value other than '-' on --markdown to fail")
+    }
+}
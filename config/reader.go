@@ -27,6 +27,137 @@ type Config struct {
     // The path of the microblog RSS file. Represented by "microblog_rss_file"
     // in the config file, expects a string.
     MicroblogRssFile string
+    // The strategy used to generate new post IDs, one of "timestamp",
+    // "random" or "slug". Represented by "microblog_id_strategy" in the
+    // config file, expects a string.
+    MicroblogIDStrategy string
+    // The path of a text/template file defining the templates of new posts,
+    // one named template per kind. Represented by "microblog_post_template"
+    // in the config file, expects a string.
+    MicroblogPostTemplate string
+    // The layout of the visible date of posts, a Go layout or strftime-style
+    // layout when containing '%'. Represented by "microblog_date_layout" in
+    // the config file, expects a string.
+    MicroblogDateLayout string
+    // The locale of month and weekday names in the visible date of posts,
+    // e.g. "en" or "fr". Represented by "microblog_locale" in the config
+    // file, expects a string.
+    MicroblogLocale string
+    // The IANA time zone of post datetimes, e.g. "Europe/London".
+    // Represented by "microblog_timezone" in the config file, expects a
+    // string.
+    MicroblogTimezone string
+    // The language of generated feeds as an RFC 5646 tag, e.g. "en-GB".
+    // Represented by "microblog_feed_language" in the config file, expects
+    // a string.
+    MicroblogFeedLanguage string
+    // The maximum length in runes of post titles derived from the first
+    // sentence of a post. Represented by "microblog_title_length" in the
+    // config file, expects an integer.
+    MicroblogTitleLength int
+    // The maximum length in runes of the plain text summary of posts.
+    // Represented by "microblog_summary_length" in the config file, expects
+    // an integer.
+    MicroblogSummaryLength int
+    // The URL the microblog page is published at, the link of feeds and base of
+    // post permalinks. Represented by "microblog_url" in the config file,
+    // expects a string.
+    MicroblogUrl string
+    // The URL the generated feed is published at, used by self links.
+    // Represented by "microblog_feed_url" in the config file, expects a
+    // string.
+    MicroblogFeedUrl string
+    // The title of generated feeds. Represented by "microblog_feed_title" in
+    // the config file, expects a string.
+    MicroblogFeedTitle string
+    // The description of generated feeds. Represented by
+    // "microblog_feed_description" in the config file, expects a string.
+    MicroblogFeedDescription string
+    // The author of generated feeds, a name or email address. Represented by
+    // "microblog_feed_author" in the config file, expects a string.
+    MicroblogFeedAuthor string
+    // The copyright notice of generated feeds. Represented by
+    // "microblog_feed_copyright" in the config file, expects a string.
+    MicroblogFeedCopyright string
+    // The email address of the editor of generated feeds. Represented by
+    // "microblog_feed_managing_editor" in the config file, expects a string.
+    MicroblogFeedManagingEditor string
+    // The email address of the web master of generated feeds. Represented by
+    // "microblog_feed_web_master" in the config file, expects a string.
+    MicroblogFeedWebMaster string
+    // The generator named by generated feeds. Represented by
+    // "microblog_feed_generator" in the config file, expects a string.
+    MicroblogFeedGenerator string
+    // The URL of the format documentation linked by generated RSS feeds.
+    // Represented by "microblog_feed_docs" in the config file, expects a
+    // string.
+    MicroblogFeedDocs string
+    // The minutes generated RSS feeds can be cached for. Represented by
+    // "microblog_feed_ttl" in the config file, expects an integer.
+    MicroblogFeedTTL int
+    // The URL of the image of generated feeds. Represented by
+    // "microblog_feed_image" in the config file, expects a string.
+    MicroblogFeedImage string
+    // The comma separated categories of generated feeds. Represented by
+    // "microblog_feed_categories" in the config file, expects a string.
+    MicroblogFeedCategories string
+    // The comma separated hours (0-23 GMT) readers should skip generated RSS
+    // feeds. Represented by "microblog_feed_skip_hours" in the config file,
+    // expects a string.
+    MicroblogFeedSkipHours string
+    // The comma separated days (e.g. Saturday) readers should skip generated
+    // RSS feeds. Represented by "microblog_feed_skip_days" in the config
+    // file, expects a string.
+    MicroblogFeedSkipDays string
+    // Whether generated RSS feeds put escaped post HTML in <description>
+    // rather than a plain text summary with the HTML in content:encoded.
+    // Represented by "microblog_feed_legacy_description" in the config file,
+    // expects a boolean.
+    MicroblogFeedLegacyDescription bool
+    // The default maximum number of posts in generated feeds, all posts when
+    // 0. Represented by "microblog_feed_limit" in the config file, expects
+    // an integer.
+    MicroblogFeedLimit int
+    // The default date of the oldest post in generated feeds, in any form
+    // accepted by microblog new --date (e.g. "-52w"). Represented by
+    // "microblog_feed_since" in the config file, expects a string.
+    MicroblogFeedSince string
+    // The default date of the newest post in generated feeds. Represented by
+    // "microblog_feed_until" in the config file, expects a string.
+    MicroblogFeedUntil string
+    // How generated feeds are archived (RFC 5005), "year" for an archive per
+    // year or a number of posts per archive, not archived when empty.
+    // Represented by "microblog_feed_archive" in the config file, expects
+    // "year" or an integer.
+    MicroblogFeedArchive string
+    // Rules extending the allowlist of HTML in generated RSS feeds, separated
+    // by commas: an element (e.g. "iframe"), an attribute of an element (e.g.
+    // "iframe@src"), an attribute of every element (e.g. "@class") or a URL
+    // scheme (e.g. "magnet:"). Represented by "microblog_rss_allow" in the
+    // config file, expects a string.
+    MicroblogRssAllow string
+    // Rules extending the allowlist of HTML in generated Atom feeds, see
+    // MicroblogRssAllow. Represented by "microblog_atom_allow" in the config
+    // file, expects a string.
+    MicroblogAtomAllow string
+    // Rules extending the allowlist of HTML in generated JSON feeds, see
+    // MicroblogRssAllow. Represented by "microblog_json_allow" in the config
+    // file, expects a string.
+    MicroblogJsonAllow string
+    // The URL the root of the site is published at, e.g. "http://yarrie.net".
+    // Represented by "site_url" in the config file, expects a string.
+    SiteUrl string
+    // The local directory the site is published from, used to find the files
+    // of media referenced by posts. Represented by "site_root" in the config
+    // file, expects a string.
+    SiteRoot string
+    // The directory backups of changed files are stored in. Represented by
+    // "backup_dir" in the config file, expects a string.
+    BackupDir string
+    // The number of backups kept for each changed file, 0 uses the default
+    // and a negative number disables backups. Represented by
+    // "backup_generations" in the config file, expects an integer.
+    BackupGenerations int
 }
 
 // Represents the states that the string reader within parseValue uses.
@@ -151,6 +282,253 @@ func updateConfig(config *Config, key string, value string) error {
         } else {
             return fmt.Errorf("'%s' expects a string value", key)
         }
+    case "microblog_id_strategy":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogIDStrategy = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_post_template":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogPostTemplate = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_date_layout":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogDateLayout = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_locale":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogLocale = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_timezone":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogTimezone = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_language":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedLanguage = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_title_length":
+        // confirm and set value as integer
+        if i, ok := parsedValue.(int); ok {
+            config.MicroblogTitleLength = i
+        } else {
+            return fmt.Errorf("'%s' expects an integer value", key)
+        }
+    case "microblog_summary_length":
+        // confirm and set value as integer
+        if i, ok := parsedValue.(int); ok {
+            config.MicroblogSummaryLength = i
+        } else {
+            return fmt.Errorf("'%s' expects an integer value", key)
+        }
+    case "microblog_url":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogUrl = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_url":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedUrl = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_title":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedTitle = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_description":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedDescription = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_author":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedAuthor = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_copyright":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedCopyright = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_managing_editor":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedManagingEditor = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_web_master":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedWebMaster = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_generator":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedGenerator = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_docs":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedDocs = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_ttl":
+        // confirm and set value as integer
+        if i, ok := parsedValue.(int); ok {
+            config.MicroblogFeedTTL = i
+        } else {
+            return fmt.Errorf("'%s' expects an integer value", key)
+        }
+    case "microblog_feed_image":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedImage = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_categories":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedCategories = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_skip_hours":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedSkipHours = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_skip_days":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedSkipDays = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_legacy_description":
+        // confirm and set value as boolean
+        if b, ok := parsedValue.(bool); ok {
+            config.MicroblogFeedLegacyDescription = b
+        } else {
+            return fmt.Errorf("'%s' expects a boolean value", key)
+        }
+    case "microblog_feed_limit":
+        // confirm and set value as integer
+        if i, ok := parsedValue.(int); ok {
+            config.MicroblogFeedLimit = i
+        } else {
+            return fmt.Errorf("'%s' expects an integer value", key)
+        }
+    case "microblog_feed_since":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedSince = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_until":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogFeedUntil = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_feed_archive":
+        // confirm and set value as "year" or an integer
+        if s, ok := parsedValue.(string); ok && s == "year" {
+            config.MicroblogFeedArchive = s
+        } else if i, ok := parsedValue.(int); ok && i > 0 {
+            config.MicroblogFeedArchive = strconv.Itoa(i)
+        } else {
+            return fmt.Errorf("'%s' expects \"year\" or a positive integer value", key)
+        }
+    case "microblog_rss_allow":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogRssAllow = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_atom_allow":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogAtomAllow = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "microblog_json_allow":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.MicroblogJsonAllow = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "site_url":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.SiteUrl = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "site_root":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.SiteRoot = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "backup_dir":
+        // confirm and set value as string
+        if s, ok := parsedValue.(string); ok {
+            config.BackupDir = s
+        } else {
+            return fmt.Errorf("'%s' expects a string value", key)
+        }
+    case "backup_generations":
+        // confirm and set value as integer
+        if i, ok := parsedValue.(int); ok {
+            config.BackupGenerations = i
+        } else {
+            return fmt.Errorf("'%s' expects an integer value", key)
+        }
     default:
         // invalid key is provided
         return fmt.Errorf("'%s' is not a valid key", key)
@@ -109,6 +109,90 @@ func TestUpdateConfig(t *testing.T) {
         }
     }
 
+    // testing value change of every other key, read back from the config
+    var valid = []struct {
+        key string
+        value string
+        expected any
+        get func(c *Config) any
+    }{
+        {"microblog_id_strategy", `"slug"`, "slug", func(c *Config) any { return c.MicroblogIDStrategy }},
+        {"microblog_post_template", `"~/post.tmpl"`, "~/post.tmpl", func(c *Config) any { return c.MicroblogPostTemplate }},
+        {"microblog_date_layout", `"%-d %B %Y"`, "%-d %B %Y", func(c *Config) any { return c.MicroblogDateLayout }},
+        {"microblog_locale", `"fr"`, "fr", func(c *Config) any { return c.MicroblogLocale }},
+        {"microblog_timezone", `"Europe/London"`, "Europe/London", func(c *Config) any { return c.MicroblogTimezone }},
+        {"microblog_feed_language", `"en-GB"`, "en-GB", func(c *Config) any { return c.MicroblogFeedLanguage }},
+        {"microblog_title_length", "40", 40, func(c *Config) any { return c.MicroblogTitleLength }},
+        {"microblog_summary_length", "200", 200, func(c *Config) any { return c.MicroblogSummaryLength }},
+        {"microblog_url", `"http://yarrie.net/microblog"`, "http://yarrie.net/microblog", func(c *Config) any { return c.MicroblogUrl }},
+        {"microblog_feed_url", `"http://yarrie.net/rss.xml"`, "http://yarrie.net/rss.xml", func(c *Config) any { return c.MicroblogFeedUrl }},
+        {"microblog_feed_title", `"yarrie"`, "yarrie", func(c *Config) any { return c.MicroblogFeedTitle }},
+        {"microblog_feed_description", `"a \"microblog\""`, `a "microblog"`, func(c *Config) any { return c.MicroblogFeedDescription }},
+        {"microblog_feed_author", `"yarrie"`, "yarrie", func(c *Config) any { return c.MicroblogFeedAuthor }},
+        {"microblog_feed_copyright", `"CC BY 4.0"`, "CC BY 4.0", func(c *Config) any { return c.MicroblogFeedCopyright }},
+        {"microblog_feed_managing_editor", `"editor@yarrie.net"`, "editor@yarrie.net", func(c *Config) any { return c.MicroblogFeedManagingEditor }},
+        {"microblog_feed_web_master", `"web@yarrie.net"`, "web@yarrie.net", func(c *Config) any { return c.MicroblogFeedWebMaster }},
+        {"microblog_feed_generator", `"yarrienet"`, "yarrienet", func(c *Config) any { return c.MicroblogFeedGenerator }},
+        {"microblog_feed_docs", `"https://www.rssboard.org/rss-specification"`, "https://www.rssboard.org/rss-specification", func(c *Config) any { return c.MicroblogFeedDocs }},
+        {"microblog_feed_ttl", "60", 60, func(c *Config) any { return c.MicroblogFeedTTL }},
+        {"microblog_feed_image", `"/icon.png"`, "/icon.png", func(c *Config) any { return c.MicroblogFeedImage }},
+        {"microblog_feed_categories", `"music, news"`, "music, news", func(c *Config) any { return c.MicroblogFeedCategories }},
+        {"microblog_feed_skip_hours", `"0, 1, 2"`, "0, 1, 2", func(c *Config) any { return c.MicroblogFeedSkipHours }},
+        {"microblog_feed_skip_days", `"Saturday"`, "Saturday", func(c *Config) any { return c.MicroblogFeedSkipDays }},
+        {"microblog_feed_legacy_description", "true", true, func(c *Config) any { return c.MicroblogFeedLegacyDescription }},
+        {"microblog_feed_limit", "20", 20, func(c *Config) any { return c.MicroblogFeedLimit }},
+        {"microblog_feed_since", `"-4w"`, "-4w", func(c *Config) any { return c.MicroblogFeedSince }},
+        {"microblog_feed_until", `"today"`, "today", func(c *Config) any { return c.MicroblogFeedUntil }},
+        {"microblog_feed_archive", `"year"`, "year", func(c *Config) any { return c.MicroblogFeedArchive }},
+        // a page size is kept as a string
+        {"microblog_feed_archive", "50", "50", func(c *Config) any { return c.MicroblogFeedArchive }},
+        {"microblog_rss_allow", `"iframe@src"`, "iframe@src", func(c *Config) any { return c.MicroblogRssAllow }},
+        {"microblog_atom_allow", `"@class"`, "@class", func(c *Config) any { return c.MicroblogAtomAllow }},
+        {"microblog_json_allow", `"magnet:"`, "magnet:", func(c *Config) any { return c.MicroblogJsonAllow }},
+        {"site_url", `"http://yarrie.net"`, "http://yarrie.net", func(c *Config) any { return c.SiteUrl }},
+        {"site_root", `"~/yarrie.net"`, "~/yarrie.net", func(c *Config) any { return c.SiteRoot }},
+        {"backup_dir", `"~/backups"`, "~/backups", func(c *Config) any { return c.BackupDir }},
+        {"backup_generations", "10", 10, func(c *Config) any { return c.BackupGenerations }},
+        // negative generations disable backups
+        {"backup_generations", "-1", -1, func(c *Config) any { return c.BackupGenerations }},
+    }
+    for _, v := range valid {
+        if err := updateConfig(&config, v.key, v.value); err != nil {
+            t.Errorf("updating config key '%s' with '%s' resulted in an error: %s", v.key, v.value, err)
+        } else if got := v.get(&config); got != v.expected {
+            t.Errorf("failed to update config '%s', expected '%v' not '%v'", v.key, v.expected, got)
+        }
+    }
+
+    // values of the wrong type for each kind of key
+    var invalid = map[string][]string{
+        "microblog_id_strategy": {"45", "true"},
+        "microblog_feed_language": {"45"},
+        "microblog_title_length": {`"40"`, "true"},
+        "microblog_summary_length": {`"200"`},
+        "microblog_feed_ttl": {`"60"`, "false"},
+        "microblog_feed_skip_hours": {"3"},
+        "microblog_feed_legacy_description": {`"true"`, "1"},
+        "microblog_feed_limit": {`"20"`},
+        "microblog_feed_since": {"7"},
+        "microblog_feed_archive": {`"month"`, `"50"`, "0", "-5", "true"},
+        "microblog_json_allow": {"false"},
+        "site_url": {"80"},
+        "backup_dir": {"true"},
+        "backup_generations": {`"5"`, "true"},
+    }
+    for key, values := range invalid {
+        for _, value := range values {
+            if err := updateConfig(&config, key, value); err == nil {
+                t.Errorf("updating config key '%s' with '%s' should result in an error", key, value)
+            }
+        }
+    }
+    // a rejected value leaves the previous value
+    if config.MicroblogFeedArchive != "50" {
+        t.Errorf("expected a rejected archive value to keep '50' not '%s'", config.MicroblogFeedArchive)
+    }
+
     // invalid key
     key, value = "microblog_invalid_file", `"x"`
     err = updateConfig(&config, key, value)
@@ -0,0 +1,159 @@
+package filehelper
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+    "time"
+)
+
+// Name of the file within the backup directory which records the paths of the
+// last changed files, one per line.
+const lastChangedFile = "last"
+
+// Layout of the timestamp suffix of each backup, sorts chronologically.
+const backupTimeLayout = "20060102T150405.000000000"
+
+// Suffix of a backup recording that the file did not exist, restored by
+// removing the file.
+const absentSuffix = ".absent"
+
+// Rotating backups of files replaced by WriteFile. Each backup is stored in
+// Dir as "<name>-<hash of path>.<timestamp>" and only the newest Generations
+// backups of each file are kept. The files written through the same Backups
+// form one change, e.g. a feed and its archives, which Undo restores
+// together.
+type Backups struct {
+    // Directory the backups are stored in, created when missing.
+    Dir string
+    // Number of backups kept for each file, must be positive.
+    Generations int
+    // Paths of the files changed through these backups, in order.
+    changed []string
+}
+
+// Prefix of the backup names of the file at the absolute path. The hash
+// distinguishes files of the same name in different directories.
+func backupPrefix(path string) string {
+    sum := sha256.Sum256([]byte(path))
+    return filepath.Base(path) + "-" + hex.EncodeToString(sum[:4]) + "."
+}
+
+// Names of the backups of the file at the absolute path, oldest first.
+func (b *Backups) list(path string) ([]string, error) {
+    entries, err := os.ReadDir(b.Dir)
+    if err != nil {
+        if os.IsNotExist(err) {
+            return nil, nil
+        }
+        return nil, err
+    }
+    prefix := backupPrefix(path)
+    var names []string
+    for _, entry := range entries {
+        if strings.HasPrefix(entry.Name(), prefix) {
+            names = append(names, entry.Name())
+        }
+    }
+    slices.Sort(names)
+    return names, nil
+}
+
+// Save the previous version of the file at the absolute path as the newest
+// backup, or a marker that the file did not exist when existed is false,
+// remove backups beyond the number of generations and record the path with
+// the other files of the change as the last changed files.
+func (b *Backups) save(path string, previous []byte, existed bool) error {
+    if b.Generations < 1 {
+        return fmt.Errorf("backup generations must be positive")
+    }
+    if err := os.MkdirAll(b.Dir, 0755); err != nil {
+        return err
+    }
+    name := backupPrefix(path) + time.Now().Format(backupTimeLayout)
+    if !existed {
+        name += absentSuffix
+    }
+    if err := os.WriteFile(filepath.Join(b.Dir, name), previous, 0644); err != nil {
+        return err
+    }
+
+    names, err := b.list(path)
+    if err != nil {
+        return err
+    }
+    for len(names) > b.Generations {
+        if err = os.Remove(filepath.Join(b.Dir, names[0])); err != nil {
+            return err
+        }
+        names = names[1:]
+    }
+    if !slices.Contains(b.changed, path) {
+        b.changed = append(b.changed, path)
+    }
+    return os.WriteFile(filepath.Join(b.Dir, lastChangedFile), []byte(strings.Join(b.changed, "\n")), 0644)
+}
+
+// Restore the newest backup of each of the last changed files, removing a
+// file when the change created it. The restored backups are removed so that
+// each undo steps back one generation. Returns the paths of the restored
+// files, an error when there is no backup to restore.
+func (b *Backups) Undo() ([]string, error) {
+    data, err := os.ReadFile(filepath.Join(b.Dir, lastChangedFile))
+    if err != nil {
+        if os.IsNotExist(err) {
+            return nil, fmt.Errorf("no changes to undo")
+        }
+        return nil, err
+    }
+    var paths []string
+    for _, path := range strings.Split(string(data), "\n") {
+        if path != "" {
+            paths = append(paths, path)
+        }
+    }
+
+    // every file must have a backup before any is restored
+    newest := make([]string, len(paths))
+    for i, path := range paths {
+        names, err := b.list(path)
+        if err != nil {
+            return nil, err
+        }
+        if len(names) == 0 {
+            return nil, fmt.Errorf("no backups of %s left to restore", path)
+        }
+        newest[i] = filepath.Join(b.Dir, names[len(names)-1])
+    }
+    for i, path := range paths {
+        if err = restore(path, newest[i]); err != nil {
+            return nil, err
+        }
+    }
+    return paths, nil
+}
+
+// Restore the file at the path from the backup, removing the file when the
+// backup records its absence, then remove the backup.
+func restore(path string, backup string) error {
+    if strings.HasSuffix(backup, absentSuffix) {
+        // the change created the file
+        if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+            return err
+        }
+        return os.Remove(backup)
+    }
+    previous, err := os.ReadFile(backup)
+    if err != nil {
+        return err
+    }
+    // restoring must not create a backup of its own
+    if err = WriteFile(path, previous, nil); err != nil {
+        return err
+    }
+    return os.Remove(backup)
+}
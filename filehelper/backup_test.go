@@ -0,0 +1,164 @@
+package filehelper
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// Testing WriteFile rotating backups and Undo restoring them in reverse
+// order.
+func TestBackupsUndo(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "index.html")
+    backups := &Backups{
+        Dir: filepath.Join(dir, "backups"),
+        Generations: 2,
+    }
+
+    // the first write records that the file did not exist
+    for _, v := range []string{"v1", "v2", "v3", "v4"} {
+        if err := WriteFile(path, []byte(v), backups); err != nil {
+            t.Fatalf("failed to write %s: %s", v, err)
+        }
+    }
+    names, err := backups.list(path)
+    if err != nil {
+        t.Fatalf("failed to list backups: %s", err)
+    }
+    if len(names) != 2 {
+        t.Fatalf("expected 2 backup generations not %d: %v", len(names), names)
+    }
+
+    // undo steps back through the kept generations
+    for _, expected := range []string{"v3", "v2"} {
+        restored, err := backups.Undo()
+        if err != nil {
+            t.Fatalf("failed to undo: %s", err)
+        }
+        if len(restored) != 1 || restored[0] != path {
+            t.Errorf("expected restored path '%s' not '%s'", path, restored)
+        }
+        data, _ := os.ReadFile(path)
+        if string(data) != expected {
+            t.Errorf("expected restored contents '%s' not '%s'", expected, data)
+        }
+    }
+    if _, err = backups.Undo(); err == nil {
+        t.Errorf("expected an error once all backups are restored")
+    }
+
+    // no temporary files are left behind
+    entries, _ := os.ReadDir(dir)
+    if len(entries) != 2 {
+        t.Errorf("expected only the file and backup directory not %d entries", len(entries))
+    }
+}
+
+// Testing that undoing the write of a new file removes it rather than
+// restoring the file changed before it.
+func TestBackupsUndoNewFile(t *testing.T) {
+    dir := t.TempDir()
+    existing := filepath.Join(dir, "index.html")
+    created := filepath.Join(dir, "rss.xml")
+    backups := &Backups{
+        Dir: filepath.Join(dir, "backups"),
+        Generations: 5,
+    }
+    if err := os.WriteFile(existing, []byte("v1"), 0644); err != nil {
+        t.Fatalf("failed to create file: %s", err)
+    }
+    if err := WriteFile(existing, []byte("v2"), backups); err != nil {
+        t.Fatalf("failed to write: %s", err)
+    }
+    // a later command with its own backups
+    backups = &Backups{Dir: backups.Dir, Generations: backups.Generations}
+    if err := WriteFile(created, []byte("feed"), backups); err != nil {
+        t.Fatalf("failed to write new file: %s", err)
+    }
+
+    restored, err := backups.Undo()
+    if err != nil {
+        t.Fatalf("failed to undo: %s", err)
+    }
+    if len(restored) != 1 || restored[0] != created {
+        t.Errorf("expected undo to restore '%s' not '%s'", created, restored)
+    }
+    if _, err := os.Stat(created); !os.IsNotExist(err) {
+        t.Errorf("expected the new file to be removed (%v)", err)
+    }
+    if data, _ := os.ReadFile(existing); string(data) != "v2" {
+        t.Errorf("expected the file changed before to be left as 'v2' not '%s'", data)
+    }
+}
+
+// Testing that writing through a symlink replaces the file it points to and
+// keeps the link.
+func TestWriteFileSymlink(t *testing.T) {
+    // the temporary directory may itself be behind a symlink
+    dir, err := filepath.EvalSymlinks(t.TempDir())
+    if err != nil {
+        t.Fatalf("failed to resolve temporary directory: %s", err)
+    }
+    target := filepath.Join(dir, "index.html")
+    link := filepath.Join(dir, "link.html")
+    if err := os.WriteFile(target, []byte("v1"), 0644); err != nil {
+        t.Fatalf("failed to create file: %s", err)
+    }
+    if err := os.Symlink(target, link); err != nil {
+        t.Skipf("symlinks unsupported: %s", err)
+    }
+    backups := &Backups{
+        Dir: filepath.Join(dir, "backups"),
+        Generations: 5,
+    }
+    if err := WriteFile(link, []byte("v2"), backups); err != nil {
+        t.Fatalf("failed to write: %s", err)
+    }
+    if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+        t.Errorf("expected the link to be kept (%v)", err)
+    }
+    if data, _ := os.ReadFile(target); string(data) != "v2" {
+        t.Errorf("expected the target to be written not '%s'", data)
+    }
+    if restored, err := backups.Undo(); err != nil || len(restored) != 1 || restored[0] != target {
+        t.Errorf("expected undo to restore the target, got '%s' (%v)", restored, err)
+    }
+    if data, _ := os.ReadFile(target); string(data) != "v1" {
+        t.Errorf("expected the target to be restored not '%s'", data)
+    }
+}
+
+// Testing that the files written through the same backups are undone
+// together.
+func TestBackupsUndoChange(t *testing.T) {
+    dir := t.TempDir()
+    feed := filepath.Join(dir, "rss.xml")
+    archive := filepath.Join(dir, "rss-2024.xml")
+    if err := os.WriteFile(feed, []byte("feed v1"), 0644); err != nil {
+        t.Fatalf("failed to create file: %s", err)
+    }
+    backups := &Backups{
+        Dir: filepath.Join(dir, "backups"),
+        Generations: 5,
+    }
+    for path, data := range map[string]string{feed: "feed v2", archive: "archive"} {
+        if err := WriteFile(path, []byte(data), backups); err != nil {
+            t.Fatalf("failed to write: %s", err)
+        }
+    }
+
+    restored, err := backups.Undo()
+    if err != nil {
+        t.Fatalf("failed to undo: %s", err)
+    }
+    if len(restored) != 2 {
+        t.Errorf("expected both files to be restored not %v", restored)
+    }
+    if data, _ := os.ReadFile(feed); string(data) != "feed v1" {
+        t.Errorf("expected the feed to be restored not '%s'", data)
+    }
+    if _, err := os.Stat(archive); !os.IsNotExist(err) {
+        t.Errorf("expected the new archive to be removed (%v)", err)
+    }
+}
@@ -0,0 +1,74 @@
+// Package filehelper provides helper functions for safely writing files.
+package filehelper
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+)
+
+// Write the data to the file at the path atomically. The data is written to
+// a temporary file in the same directory which is then renamed over the path,
+// so the file is never left partially written. The permissions of an existing
+// file are kept, new files are created with 0644. A symlink is followed so
+// that the file it points to is replaced rather than the link.
+//
+// When backups is not nil the previous version of the file, or its absence,
+// is saved in the backup directory once it has been replaced, see Backups.
+func WriteFile(path string, data []byte, backups *Backups) error {
+    path, err := filepath.Abs(path)
+    if err != nil {
+        return err
+    }
+    if resolved, err := filepath.EvalSymlinks(path); err == nil {
+        path = resolved
+    } else if !os.IsNotExist(err) {
+        return err
+    }
+    var mode os.FileMode = 0644
+    previous, err := os.ReadFile(path)
+    existed := err == nil
+    if existed {
+        if info, err := os.Stat(path); err == nil {
+            mode = info.Mode().Perm()
+        }
+    } else if !os.IsNotExist(err) {
+        return err
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(path), "." + filepath.Base(path) + ".tmp*")
+    if err != nil {
+        return err
+    }
+    // removing fails harmlessly once the file has been renamed
+    defer os.Remove(tmp.Name())
+
+    if _, err = tmp.Write(data); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err = tmp.Sync(); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err = tmp.Close(); err != nil {
+        return err
+    }
+    if err = os.Chmod(tmp.Name(), mode); err != nil {
+        return err
+    }
+    if err = os.Rename(tmp.Name(), path); err != nil {
+        return err
+    }
+
+    // back up the previous version only once it has been replaced
+    if backups != nil {
+        if !existed {
+            previous = nil
+        }
+        if err = backups.save(path, previous, existed); err != nil {
+            return fmt.Errorf("%s was written but could not be backed up: %w", path, err)
+        }
+    }
+    return nil
+}
@@ -0,0 +1,72 @@
+package htmlhelper
+
+import (
+    "golang.org/x/net/html"
+    "fmt"
+    "io"
+    "strings"
+)
+
+// Elements which never contain children and have no end tag.
+var voidElements = map[string]bool{
+    "area": true, "base": true, "br": true, "col": true, "embed": true,
+    "hr": true, "img": true, "input": true, "link": true, "meta": true,
+    "source": true, "track": true, "wbr": true,
+}
+
+// Elements whose end tag may be omitted and are implicitly closed by their
+// parent's end tag.
+var optionalEndElements = map[string]bool{
+    "li": true, "dt": true, "dd": true, "p": true, "rt": true, "rp": true,
+    "optgroup": true, "option": true, "thead": true, "tbody": true,
+    "tfoot": true, "tr": true, "td": true, "th": true,
+}
+
+// Check that the HTML source has balanced tags. The html package parser is
+// lenient and will accept any input, this check is used to reject hand edited
+// source which is most likely a mistake, e.g. an unclosed <a> or a stray
+// </div>. Elements with optional end tags are allowed to be left open. Returns
+// an error describing the first problem found.
+func CheckBalanced(s string) error {
+    z := html.NewTokenizer(strings.NewReader(s))
+    var stack []string
+    for {
+        tt := z.Next()
+        switch tt {
+        case html.ErrorToken:
+            if z.Err() != io.EOF {
+                return z.Err()
+            }
+            // any remaining open element must have an optional end tag
+            for i := len(stack) - 1; i >= 0; i-- {
+                if !optionalEndElements[stack[i]] {
+                    return fmt.Errorf("unclosed <%s>", stack[i])
+                }
+            }
+            return nil
+        case html.StartTagToken:
+            name, _ := z.TagName()
+            if !voidElements[string(name)] {
+                stack = append(stack, string(name))
+            }
+        case html.EndTagToken:
+            bname, _ := z.TagName()
+            name := string(bname)
+            if voidElements[name] {
+                continue
+            }
+            // find the matching open element, elements opened after it must
+            // have an optional end tag
+            i := len(stack) - 1
+            for ; i >= 0 && stack[i] != name; i-- {
+                if !optionalEndElements[stack[i]] {
+                    return fmt.Errorf("unexpected </%s>, expected </%s>", name, stack[i])
+                }
+            }
+            if i < 0 {
+                return fmt.Errorf("unexpected </%s> without matching start tag", name)
+            }
+            stack = stack[:i]
+        }
+    }
+}
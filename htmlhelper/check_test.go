@@ -0,0 +1,40 @@
+package htmlhelper
+
+import (
+    "strings"
+    "testing"
+)
+
+// Testing that CheckBalanced accepts balanced, void and optionally closed
+// elements and rejects unclosed and stray tags.
+func TestCheckBalanced(t *testing.T) {
+    valid := []string{
+        "",
+        "plain text",
+        `<p>a <a href="x">link</a></p>`,
+        `<p>line<br>break<img src="a.png"><br/></p>`,
+        `<ul><li>one<li>two</ul>`,
+        `<p>open paragraph`,
+        `<table><tr><td>a<td>b</table>`,
+    }
+    for _, s := range valid {
+        if err := CheckBalanced(s); err != nil {
+            t.Errorf("expected '%s' to be balanced, got %s", s, err)
+        }
+    }
+
+    invalid := map[string]string{
+        `<p>a <a href="x">link</p>`: "expected </a>",
+        `<div><p>text</p>`: "unclosed <div>",
+        `<p>text</p></div>`: "without matching start tag",
+        `<b><i>text</b></i>`: "expected </i>",
+    }
+    for s, expected := range invalid {
+        err := CheckBalanced(s)
+        if err == nil {
+            t.Errorf("expected '%s' to be unbalanced", s)
+        } else if !strings.Contains(err.Error(), expected) {
+            t.Errorf("expected the error of '%s' to contain '%s' not '%s'", s, expected, err)
+        }
+    }
+}
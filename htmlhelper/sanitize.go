@@ -0,0 +1,256 @@
+package htmlhelper
+
+import (
+    "golang.org/x/net/html"
+    "fmt"
+    "net/url"
+    "sort"
+    "strings"
+)
+
+// Allowlist of the elements, attributes and URL schemes kept by Sanitize.
+type SanitizePolicy struct {
+    // Elements kept. Other elements are replaced by their children unless
+    // they are in DropElements.
+    Elements map[string]bool
+    // Elements removed together with their content, e.g. script.
+    DropElements map[string]bool
+    // Attributes kept by element name, the "" entry applies to every
+    // element.
+    Attributes map[string]map[string]bool
+    // Schemes of URL attributes kept, e.g. "https". Relative URLs are
+    // always kept.
+    Schemes map[string]bool
+}
+
+// Make a set of the names.
+func nameSet(names ...string) map[string]bool {
+    set := make(map[string]bool, len(names))
+    for _, name := range names {
+        set[name] = true
+    }
+    return set
+}
+
+// Create the default policy which keeps text formatting, links, lists,
+// tables, quotes, images, audio and video. Scripts, styles, frames, forms and
+// embedded objects are dropped with their content, attributes other than
+// those describing the content (e.g. class, id, style and on* handlers) are
+// removed and URLs must be http, https or mailto.
+func NewSanitizePolicy() *SanitizePolicy {
+    return &SanitizePolicy{
+        Elements: nameSet(
+            "a", "abbr", "b", "bdi", "bdo", "blockquote", "br", "caption",
+            "cite", "code", "col", "colgroup", "dd", "del", "details", "dfn",
+            "div", "dl", "dt", "em", "figcaption", "figure", "h1", "h2", "h3",
+            "h4", "h5", "h6", "hr", "i", "img", "ins", "kbd", "li", "mark",
+            "ol", "p", "picture", "pre", "q", "rp", "rt", "ruby", "s", "samp",
+            "small", "span", "strong", "sub", "summary", "sup", "table",
+            "tbody", "td", "tfoot", "th", "thead", "time", "tr", "u", "ul",
+            "var", "wbr", "audio", "video", "source", "track",
+        ),
+        DropElements: nameSet(
+            "script", "style", "iframe", "frame", "frameset", "object",
+            "embed", "applet", "noscript", "template", "form", "input",
+            "button", "select", "textarea", "link", "meta", "base", "head",
+            "title", "svg", "math",
+        ),
+        Attributes: map[string]map[string]bool{
+            "": nameSet("title", "lang", "dir"),
+            "a": nameSet("href", "hreflang", "rel"),
+            "img": nameSet("src", "srcset", "sizes", "alt", "width", "height"),
+            "audio": nameSet("src", "controls", "loop", "muted", "preload"),
+            "video": nameSet("src", "controls", "loop", "muted", "preload", "poster", "width", "height"),
+            "source": nameSet("src", "srcset", "sizes", "type", "media"),
+            "track": nameSet("src", "kind", "srclang", "label", "default"),
+            "blockquote": nameSet("cite"),
+            "q": nameSet("cite"),
+            "del": nameSet("cite", "datetime"),
+            "ins": nameSet("cite", "datetime"),
+            "time": nameSet("datetime"),
+            "abbr": nameSet("title"),
+            "ol": nameSet("start", "reversed", "type"),
+            "li": nameSet("value"),
+            "td": nameSet("colspan", "rowspan", "headers"),
+            "th": nameSet("colspan", "rowspan", "headers", "scope", "abbr"),
+            "col": nameSet("span"),
+            "colgroup": nameSet("span"),
+            "details": nameSet("open"),
+        },
+        Schemes: nameSet("http", "https", "mailto"),
+    }
+}
+
+// Extend the policy by a rule, which is an element name (e.g. "iframe"), an
+// attribute of an element (e.g. "iframe@src"), an attribute of every element
+// (e.g. "@class") or a URL scheme (e.g. "magnet:"). Returns an error when the
+// rule is empty or malformed.
+func (p *SanitizePolicy) Allow(rule string) error {
+    rule = strings.ToLower(strings.TrimSpace(rule))
+    if scheme, ok := strings.CutSuffix(rule, ":"); ok {
+        if scheme == "" || strings.ContainsAny(scheme, ":@ ") {
+            return fmt.Errorf("invalid url scheme '%s'", rule)
+        }
+        p.Schemes[scheme] = true
+        return nil
+    }
+    element, attr, isAttr := strings.Cut(rule, "@")
+    if isAttr {
+        if attr == "" || strings.ContainsAny(attr, ":@ ") {
+            return fmt.Errorf("invalid attribute rule '%s'", rule)
+        }
+        if p.Attributes[element] == nil {
+            p.Attributes[element] = map[string]bool{}
+        }
+        p.Attributes[element][attr] = true
+        return nil
+    }
+    if element == "" || strings.ContainsAny(element, ": ") {
+        return fmt.Errorf("invalid element rule '%s'", rule)
+    }
+    p.Elements[element] = true
+    delete(p.DropElements, element)
+    return nil
+}
+
+// What Sanitize removed from nodes.
+type SanitizeReport struct {
+    // Number of removed elements by name.
+    Elements map[string]int
+    // Number of removed attributes by name, including URL attributes
+    // removed for their scheme.
+    Attributes map[string]int
+    // URLs removed for their scheme, e.g. "javascript:alert(1)".
+    URLs []string
+    // Number of removed comments.
+    Comments int
+}
+
+// Whether nothing was removed.
+func (r *SanitizeReport) Empty() bool {
+    return len(r.Elements) == 0 && len(r.Attributes) == 0 && len(r.URLs) == 0 && r.Comments == 0
+}
+
+// Format counts sorted by name, e.g. "<script> x2".
+func formatCounts(counts map[string]int, format string) []string {
+    var names []string
+    for name := range counts {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    var s []string
+    for _, name := range names {
+        entry := fmt.Sprintf(format, name)
+        if counts[name] > 1 {
+            entry += fmt.Sprintf(" x%d", counts[name])
+        }
+        s = append(s, entry)
+    }
+    return s
+}
+
+// Summarise the report on one line, e.g.
+// "<script>, onclick x2, url javascript:alert(1)".
+func (r *SanitizeReport) String() string {
+    s := formatCounts(r.Elements, "<%s>")
+    s = append(s, formatCounts(r.Attributes, "%s")...)
+    for _, u := range r.URLs {
+        s = append(s, "url " + u)
+    }
+    if r.Comments == 1 {
+        s = append(s, "comment")
+    } else if r.Comments > 1 {
+        s = append(s, fmt.Sprintf("comment x%d", r.Comments))
+    }
+    return strings.Join(s, ", ")
+}
+
+// Whether the scheme of a URL is allowed, relative URLs are always allowed.
+func (p *SanitizePolicy) allowedURL(s string) bool {
+    u, err := url.Parse(strings.TrimSpace(s))
+    if err != nil {
+        return false
+    }
+    return u.Scheme == "" || p.Schemes[strings.ToLower(u.Scheme)]
+}
+
+// Remove the attributes of an element not allowed by the policy.
+func (p *SanitizePolicy) sanitizeAttrs(node *html.Node, report *SanitizeReport) {
+    var kept []html.Attribute
+    for _, attr := range node.Attr {
+        key := strings.ToLower(attr.Key)
+        allowed := attr.Namespace == "" && (p.Attributes[""][key] || p.Attributes[node.Data][key])
+        if allowed && key == "srcset" {
+            for _, candidate := range strings.Split(attr.Val, ",") {
+                fields := strings.Fields(candidate)
+                if len(fields) > 0 && !p.allowedURL(fields[0]) {
+                    report.URLs = append(report.URLs, fields[0])
+                    allowed = false
+                }
+            }
+        } else if allowed && urlAttrs[key] && !p.allowedURL(attr.Val) {
+            report.URLs = append(report.URLs, attr.Val)
+            allowed = false
+        }
+        if allowed {
+            kept = append(kept, attr)
+        } else {
+            report.Attributes[key]++
+        }
+    }
+    node.Attr = kept
+}
+
+// Sanitise the children of a node in place.
+func (p *SanitizePolicy) sanitizeChildren(parent *html.Node, report *SanitizeReport) {
+    for c := parent.FirstChild; c != nil; {
+        next := c.NextSibling
+        switch c.Type {
+        case html.CommentNode:
+            parent.RemoveChild(c)
+            report.Comments++
+        case html.ElementNode:
+            if p.DropElements[c.Data] {
+                parent.RemoveChild(c)
+                report.Elements[c.Data]++
+                break
+            }
+            p.sanitizeChildren(c, report)
+            if p.Elements[c.Data] {
+                p.sanitizeAttrs(c, report)
+                break
+            }
+            // keep the content of other elements
+            for gc := c.FirstChild; gc != nil; gc = c.FirstChild {
+                c.RemoveChild(gc)
+                parent.InsertBefore(gc, c)
+            }
+            parent.RemoveChild(c)
+            report.Elements[c.Data]++
+        }
+        c = next
+    }
+}
+
+// Copy the nodes keeping only the elements, attributes and URLs allowed by
+// the policy. Comments are removed. The nodes themselves are not modified.
+// Returns the copies and a report of what was removed.
+func Sanitize(nodes []*html.Node, policy *SanitizePolicy) ([]*html.Node, *SanitizeReport) {
+    report := &SanitizeReport{
+        Elements: map[string]int{},
+        Attributes: map[string]int{},
+    }
+    // sanitise the copies as the children of a container so that the nodes
+    // themselves can be removed or unwrapped
+    container := &html.Node{Type: html.ElementNode, Data: "body"}
+    for _, node := range nodes {
+        container.AppendChild(CloneNode(node))
+    }
+    policy.sanitizeChildren(container, report)
+    var sanitized []*html.Node
+    for c := container.FirstChild; c != nil; c = container.FirstChild {
+        container.RemoveChild(c)
+        sanitized = append(sanitized, c)
+    }
+    return sanitized, report
+}
@@ -0,0 +1,80 @@
+package htmlhelper
+
+import (
+    "golang.org/x/net/html"
+    "golang.org/x/net/html/atom"
+    "strings"
+    "testing"
+)
+
+// Parse an HTML fragment within a body element.
+func parseFragment(t *testing.T, src string) []*html.Node {
+    t.Helper()
+    body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+    nodes, err := html.ParseFragment(strings.NewReader(src), body)
+    if err != nil {
+        t.Fatalf("failed to parse fragment: %s", err)
+    }
+    return nodes
+}
+
+// Render nodes as HTML.
+func renderNodes(nodes []*html.Node) string {
+    var b strings.Builder
+    for _, n := range nodes {
+        html.Render(&b, n)
+    }
+    return b.String()
+}
+
+// Testing that Sanitize drops dangerous elements with their content, unwraps
+// unknown elements, removes attributes and URLs outside of the allowlist and
+// reports what was removed.
+func TestSanitize(t *testing.T) {
+    nodes := parseFragment(t, `<p class="x" id="y" onclick="evil()">hello <font color="red">world</font><!-- note --></p>`+
+        `<script>alert(1)</script><style>p{}</style>`+
+        `<a href="javascript:alert(1)" title="t">bad</a> <a href="mailto:a@b.c">mail</a>`+
+        `<img src="http://yarrie.net/a.png" srcset="data:x 1x" alt="a" style="border:0">`+
+        `<iframe src="http://example.com/"></iframe>`)
+    before := renderNodes(nodes)
+
+    sanitized, report := Sanitize(nodes, NewSanitizePolicy())
+    expected := `<p>hello world</p>` +
+        `<a title="t">bad</a> <a href="mailto:a@b.c">mail</a>` +
+        `<img src="http://yarrie.net/a.png" alt="a"/>`
+    if got := renderNodes(sanitized); got != expected {
+        t.Errorf("unexpected sanitised html\nexpected: %s\ngot:      %s", expected, got)
+    }
+    if after := renderNodes(nodes); after != before {
+        t.Errorf("expected the original nodes to be unchanged, got %s", after)
+    }
+    expectedReport := "<font>, <iframe>, <script>, <style>, class, href, id, onclick, srcset, style, url javascript:alert(1), url data:x, comment"
+    if got := report.String(); got != expectedReport {
+        t.Errorf("unexpected report\nexpected: %s\ngot:      %s", expectedReport, got)
+    }
+}
+
+// Testing that rules extend the policy.
+func TestSanitizePolicyAllow(t *testing.T) {
+    policy := NewSanitizePolicy()
+    for _, rule := range []string{"iframe", "iframe@src", "@class", "magnet:"} {
+        if err := policy.Allow(rule); err != nil {
+            t.Fatalf("failed to allow '%s': %s", rule, err)
+        }
+    }
+    nodes := parseFragment(t, `<p class="x"><a href="magnet:?xt=1">m</a></p><iframe src="http://example.com/" width="1"></iframe>`)
+    sanitized, report := Sanitize(nodes, policy)
+    expected := `<p class="x"><a href="magnet:?xt=1">m</a></p><iframe src="http://example.com/"></iframe>`
+    if got := renderNodes(sanitized); got != expected {
+        t.Errorf("unexpected sanitised html\nexpected: %s\ngot:      %s", expected, got)
+    }
+    if report.String() != "width" {
+        t.Errorf("unexpected report '%s'", report)
+    }
+
+    for _, rule := range []string{"", ":", "a@", "a b"} {
+        if err := policy.Allow(rule); err == nil {
+            t.Errorf("expected rule '%s' to be invalid", rule)
+        }
+    }
+}
@@ -0,0 +1,196 @@
+package htmlhelper
+
+import (
+    "golang.org/x/net/html"
+    "bytes"
+    "io"
+    "strings"
+)
+
+// Element located within HTML source by byte offsets. Used to modify the
+// source in place, preserving its formatting, rather than rendering a parsed
+// document with html.Render.
+type SourceElement struct {
+    // Tag name of the element.
+    Name string
+    // ID attribute of the element.
+    ID string
+    // Classes attribute of the element.
+    Classes []string
+    // Attributes of the element, with entities unescaped.
+    Attr []html.Attribute
+    // Offset of the start of the start tag.
+    Start int
+    // Offset after the start tag, the start of the content.
+    ContentStart int
+    // Offset of the end tag, the end of the content.
+    ContentEnd int
+    // Offset after the end tag. Equal to ContentEnd when the element has no
+    // end tag (void, self-closing or implicitly closed elements).
+    End int
+    // Parent element, nil for top level elements.
+    Parent *SourceElement
+    // Child elements in source order.
+    Children []*SourceElement
+}
+
+// Report whether the element has the class.
+func (e *SourceElement) HasClass(class string) bool {
+    for _, c := range e.Classes {
+        if c == class {
+            return true
+        }
+    }
+    return false
+}
+
+// Value of the attribute, an empty string when missing.
+func (e *SourceElement) GetAttr(key string) string {
+    for _, attr := range e.Attr {
+        if attr.Key == key {
+            return attr.Val
+        }
+    }
+    return ""
+}
+
+// Find the first descendant element for which the match function returns
+// true, in source order. Returns nil when none match.
+func (e *SourceElement) Find(match func(*SourceElement) bool) *SourceElement {
+    for _, child := range e.Children {
+        if match(child) {
+            return child
+        }
+        if found := child.Find(match); found != nil {
+            return found
+        }
+    }
+    return nil
+}
+
+// Elements whose start implicitly closes an open <p>.
+var closesParagraph = map[string]bool{
+    "address": true, "article": true, "aside": true, "blockquote": true,
+    "div": true, "dl": true, "figure": true, "footer": true, "form": true,
+    "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+    "header": true, "hr": true, "main": true, "nav": true, "ol": true,
+    "p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+// Locate every element within the HTML source using the html.Tokenizer.
+// Returns the elements in source order, each linked to its parent and
+// children.
+//
+// Unlike html.Parse the tree is not corrected beyond simple implicit closing:
+// elements with optional end tags are closed by their parent's end tag or a
+// sibling of the same name, and <p> by the start of a block element. Stray
+// end tags are ignored.
+func IndexSource(data []byte) ([]*SourceElement, error) {
+    z := html.NewTokenizer(bytes.NewReader(data))
+    var elements []*SourceElement
+    var stack []*SourceElement
+    var offset = 0
+
+    // close the top most open element at the offset without an end tag
+    closeTop := func(at int) {
+        top := stack[len(stack)-1]
+        top.ContentEnd = at
+        top.End = at
+        stack = stack[:len(stack)-1]
+    }
+    // add an element as a child of the top most open element
+    add := func(e *SourceElement) {
+        if len(stack) > 0 {
+            e.Parent = stack[len(stack)-1]
+            e.Parent.Children = append(e.Parent.Children, e)
+        }
+        elements = append(elements, e)
+    }
+
+    for {
+        tt := z.Next()
+        start := offset
+        // the raw token must be measured before reading the token
+        offset += len(z.Raw())
+
+        switch tt {
+        case html.ErrorToken:
+            if z.Err() != io.EOF {
+                return nil, z.Err()
+            }
+            for len(stack) > 0 {
+                closeTop(len(data))
+            }
+            return elements, nil
+        case html.StartTagToken, html.SelfClosingTagToken:
+            tok := z.Token()
+            e := &SourceElement{
+                Name: tok.Data,
+                Attr: tok.Attr,
+                Start: start,
+                ContentStart: offset,
+            }
+            for _, attr := range tok.Attr {
+                if attr.Key == "id" {
+                    e.ID = attr.Val
+                } else if attr.Key == "class" {
+                    e.Classes = strings.Fields(attr.Val)
+                }
+            }
+
+            // implicit closing of the open elements
+            if len(stack) > 0 {
+                top := stack[len(stack)-1]
+                if top.Name == e.Name && optionalEndElements[e.Name] {
+                    closeTop(start)
+                } else if top.Name == "p" && closesParagraph[e.Name] {
+                    closeTop(start)
+                }
+            }
+
+            add(e)
+            if tt == html.SelfClosingTagToken || voidElements[e.Name] {
+                e.ContentEnd = offset
+                e.End = offset
+            } else {
+                stack = append(stack, e)
+            }
+        case html.EndTagToken:
+            tok := z.Token()
+            // find the matching open element, ignoring stray end tags
+            i := len(stack) - 1
+            for ; i >= 0 && stack[i].Name != tok.Data; i-- {}
+            if i < 0 {
+                continue
+            }
+            // elements opened after the match are implicitly closed
+            for len(stack) - 1 > i {
+                closeTop(start)
+            }
+            e := stack[i]
+            e.ContentEnd = start
+            e.End = offset
+            stack = stack[:i]
+        }
+    }
+}
+
+// Find the first element for which the match function returns true, in
+// source order. Returns nil when none match.
+func FindSourceElement(elements []*SourceElement, match func(*SourceElement) bool) *SourceElement {
+    for _, e := range elements {
+        if match(e) {
+            return e
+        }
+    }
+    return nil
+}
+
+// Replace the bytes between the start and end offsets of the source with the
+// text. Returns a new slice, the source is not modified.
+func Splice(data []byte, start int, end int, text string) []byte {
+    out := make([]byte, 0, len(data) - (end - start) + len(text))
+    out = append(out, data[:start]...)
+    out = append(out, text...)
+    return append(out, data[end:]...)
+}
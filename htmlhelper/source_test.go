@@ -0,0 +1,76 @@
+package htmlhelper
+
+import (
+    "testing"
+)
+
+// Testing IndexSource locates elements by their byte offsets, including
+// implicitly closed and void elements.
+func TestIndexSource(t *testing.T) {
+    src := `<!DOCTYPE html>
+<div id="posts">
+    <div class="post a" id="x"><p>one<p>two<br></div>
+    <ul><li>a<li>b</ul>
+</div>`
+    elements, err := IndexSource([]byte(src))
+    if err != nil {
+        t.Fatalf("failed to index source: %s", err)
+    }
+
+    // expected raw source of each element in source order
+    expected := []string{
+        `<div id="posts">
+    <div class="post a" id="x"><p>one<p>two<br></div>
+    <ul><li>a<li>b</ul>
+</div>`,
+        `<div class="post a" id="x"><p>one<p>two<br></div>`,
+        `<p>one`,
+        `<p>two<br>`,
+        `<br>`,
+        `<ul><li>a<li>b</ul>`,
+        `<li>a`,
+        `<li>b`,
+    }
+    if len(elements) != len(expected) {
+        t.Fatalf("expected %d elements not %d", len(expected), len(elements))
+    }
+    for i, e := range elements {
+        if raw := src[e.Start:e.End]; raw != expected[i] {
+            t.Errorf("expected element %d (%s) to be '%s' not '%s'", i, e.Name, expected[i], raw)
+        }
+    }
+
+    // testing content offsets, id, classes and tree
+    post := FindSourceElement(elements, func(e *SourceElement) bool {
+        return e.HasClass("post")
+    })
+    if post == nil || post.ID != "x" || !post.HasClass("a") {
+        t.Fatalf("expected to find .post.a#x not %v", post)
+    }
+    if content := src[post.ContentStart:post.ContentEnd]; content != "<p>one<p>two<br>" {
+        t.Errorf("expected post content '<p>one<p>two<br>' not '%s'", content)
+    }
+    if post.Parent == nil || post.Parent.ID != "posts" {
+        t.Errorf("expected post parent to be #posts")
+    }
+    if len(post.Children) != 2 {
+        t.Errorf("expected post to have 2 children not %d", len(post.Children))
+    }
+}
+
+// Testing Splice replaces a range without modifying the source.
+func TestSplice(t *testing.T) {
+    src := []byte("<p>hello world</p>")
+    out := Splice(src, 9, 14, "there")
+    if string(out) != "<p>hello there</p>" {
+        t.Errorf("expected '<p>hello there</p>' not '%s'", out)
+    }
+    if string(src) != "<p>hello world</p>" {
+        t.Errorf("source was modified: '%s'", src)
+    }
+    // insertion
+    out = Splice(src, 3, 3, "oh ")
+    if string(out) != "<p>oh hello world</p>" {
+        t.Errorf("expected '<p>oh hello world</p>' not '%s'", out)
+    }
+}
@@ -0,0 +1,182 @@
+package htmlhelper
+
+import (
+    "golang.org/x/net/html"
+    "fmt"
+    "strings"
+    "unicode/utf8"
+)
+
+// Elements which start a new paragraph when rendered as text.
+var blockElements = map[string]bool{
+    "address": true, "article": true, "aside": true, "blockquote": true,
+    "dd": true, "div": true, "dl": true, "dt": true, "figcaption": true,
+    "figure": true, "footer": true, "h1": true, "h2": true, "h3": true,
+    "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+    "li": true, "main": true, "nav": true, "ol": true, "p": true,
+    "pre": true, "section": true, "table": true, "tr": true, "ul": true,
+}
+
+// Elements whose content is never rendered as text.
+var hiddenElements = map[string]bool{
+    "head": true, "noscript": true, "script": true, "style": true,
+    "template": true,
+}
+
+// Plain text rendering of HTML nodes, see RenderText.
+type Text struct {
+    // Text of each block level element with whitespace collapsed. Line
+    // breaks (<br>) are kept as new lines.
+    Paragraphs []string
+    // Link targets referenced as footnotes in the paragraphs, e.g. "[1]"
+    // refers to Links[0]. Only populated when rendering with footnotes.
+    Links []string
+}
+
+// Render nodes as plain text. Block level elements become paragraphs, list
+// items are prefixed with "- " and images are replaced by "[img: alt]". When
+// footnotes is true each link is followed by a reference to its target, e.g.
+// "world [1]", and the targets are collected in Links.
+func RenderText(nodes []*html.Node, footnotes bool) *Text {
+    t := &Text{}
+    var sb strings.Builder
+    flush := func() {
+        // trim the spaces preceding line breaks, leading spaces are only
+        // written by pre elements and are kept
+        lines := strings.Split(sb.String(), "\n")
+        for i := range lines {
+            lines[i] = strings.TrimRight(lines[i], " ")
+        }
+        p := strings.Trim(strings.Join(lines, "\n"), "\n")
+        if p != "" {
+            t.Paragraphs = append(t.Paragraphs, p)
+        }
+        sb.Reset()
+    }
+    // write text collapsing whitespace into single spaces
+    write := func(s string) {
+        for _, r := range s {
+            if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+                str := sb.String()
+                if len(str) == 0 || str[len(str)-1] == ' ' || str[len(str)-1] == '\n' {
+                    continue
+                }
+                r = ' '
+            }
+            sb.WriteRune(r)
+        }
+    }
+    // pre elements keep their whitespace
+    var inPre = 0
+
+    var f func(n *html.Node)
+    f = func(n *html.Node) {
+        switch n.Type {
+        case html.TextNode:
+            if inPre > 0 {
+                sb.WriteString(n.Data)
+            } else {
+                write(n.Data)
+            }
+            return
+        case html.ElementNode:
+        case html.DocumentNode:
+        default:
+            return
+        }
+
+        name := n.Data
+        if hiddenElements[name] {
+            return
+        }
+        switch name {
+        case "br":
+            sb.WriteString("\n")
+            return
+        case "img":
+            alt := strings.TrimSpace(GetNodeAttr(n, "alt"))
+            if alt == "" {
+                write("[img]")
+            } else {
+                write("[img: " + alt + "]")
+            }
+            return
+        }
+
+        block := blockElements[name]
+        if block {
+            flush()
+        }
+        if name == "li" {
+            sb.WriteString("- ")
+        } else if name == "pre" {
+            inPre++
+        }
+        for c := n.FirstChild; c != nil; c = c.NextSibling {
+            f(c)
+        }
+        if name == "pre" {
+            inPre--
+        }
+        if name == "a" && footnotes {
+            if href := GetNodeAttr(n, "href"); href != "" {
+                t.Links = append(t.Links, href)
+                write(fmt.Sprintf(" [%d]", len(t.Links)))
+            }
+        }
+        if block {
+            flush()
+        }
+    }
+    for _, n := range nodes {
+        f(n)
+    }
+    flush()
+    return t
+}
+
+// The paragraphs joined by blank lines, without footnotes.
+func (t *Text) String() string {
+    return strings.Join(t.Paragraphs, "\n\n")
+}
+
+// The paragraphs wrapped to the given width and joined by blank lines,
+// followed by the footnotes if any. Words longer than the width are not
+// broken.
+func (t *Text) Wrap(width int) string {
+    var blocks []string
+    for _, p := range t.Paragraphs {
+        var lines []string
+        for _, line := range strings.Split(p, "\n") {
+            lines = append(lines, wrapLine(line, width)...)
+        }
+        blocks = append(blocks, strings.Join(lines, "\n"))
+    }
+    if len(t.Links) > 0 {
+        var notes []string
+        for i, link := range t.Links {
+            notes = append(notes, fmt.Sprintf("[%d] %s", i+1, link))
+        }
+        blocks = append(blocks, strings.Join(notes, "\n"))
+    }
+    return strings.Join(blocks, "\n\n")
+}
+
+// Word wrap a single line to the width.
+func wrapLine(line string, width int) []string {
+    words := strings.Fields(line)
+    if len(words) == 0 {
+        return []string{""}
+    }
+    var lines []string
+    current := words[0]
+    for _, w := range words[1:] {
+        if utf8.RuneCountInString(current) + 1 + utf8.RuneCountInString(w) > width {
+            lines = append(lines, current)
+            current = w
+        } else {
+            current += " " + w
+        }
+    }
+    return append(lines, current)
+}
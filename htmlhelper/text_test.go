@@ -0,0 +1,62 @@
+package htmlhelper
+
+import (
+    "reflect"
+    "testing"
+)
+
+// Testing that RenderText splits block elements into paragraphs, collapses
+// whitespace, keeps line breaks and pre elements and replaces images by
+// their alt text.
+func TestRenderText(t *testing.T) {
+    nodes := parseFragment(t, `<p>hello
+        <b>world</b></p><ul><li>one</li><li>two</li></ul>`+
+        `<p>line<br>break <img src="a.png" alt=" a cat "> <img src="b.png"></p>`+
+        `<pre>  keep
+    spacing</pre><script>hidden()</script>`)
+    text := RenderText(nodes, false)
+    expected := []string{
+        "hello world",
+        "- one",
+        "- two",
+        "line\nbreak [img: a cat] [img]",
+        "  keep\n    spacing",
+    }
+    if !reflect.DeepEqual(text.Paragraphs, expected) {
+        t.Errorf("unexpected paragraphs\nexpected: %q\ngot:      %q", expected, text.Paragraphs)
+    }
+    if text.Links != nil {
+        t.Errorf("expected no links without footnotes, got %v", text.Links)
+    }
+}
+
+// Testing that RenderText with footnotes references each link target in
+// order.
+func TestRenderTextFootnotes(t *testing.T) {
+    nodes := parseFragment(t, `<p>see <a href="https://example.com">this</a> and <a href="/a">that</a>, <a>not a link</a></p>`)
+    text := RenderText(nodes, true)
+    if text.String() != "see this [1] and that [2], not a link" {
+        t.Errorf("unexpected text with footnotes '%s'", text.String())
+    }
+    if !reflect.DeepEqual(text.Links, []string{"https://example.com", "/a"}) {
+        t.Errorf("unexpected links %v", text.Links)
+    }
+}
+
+// Testing that Wrap wraps paragraphs at the width without breaking long
+// words and follows them with the footnotes.
+func TestTextWrap(t *testing.T) {
+    text := &Text{
+        Paragraphs: []string{
+            "the quick brown fox jumps over the lazy dog",
+            "kept\nlines and an unbreakable-word",
+        },
+        Links: []string{"https://example.com"},
+    }
+    expected := "the quick brown\nfox jumps over\nthe lazy dog\n\n" +
+        "kept\nlines and an\nunbreakable-word\n\n" +
+        "[1] https://example.com"
+    if got := text.Wrap(15); got != expected {
+        t.Errorf("unexpected wrapped text\nexpected: %q\ngot:      %q", expected, got)
+    }
+}
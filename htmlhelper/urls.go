@@ -0,0 +1,85 @@
+package htmlhelper
+
+import (
+    "golang.org/x/net/html"
+    "net/url"
+    "strings"
+)
+
+// Attributes of elements holding a URL.
+var urlAttrs = map[string]bool{
+    "href": true, "src": true, "poster": true, "cite": true,
+}
+
+// Copy a node and its descendants. The copy has no parent or siblings.
+func CloneNode(node *html.Node) *html.Node {
+    clone := &html.Node{
+        Type: node.Type,
+        DataAtom: node.DataAtom,
+        Data: node.Data,
+        Namespace: node.Namespace,
+        Attr: append([]html.Attribute(nil), node.Attr...),
+    }
+    for c := node.FirstChild; c != nil; c = c.NextSibling {
+        clone.AppendChild(CloneNode(c))
+    }
+    return clone
+}
+
+// Resolve a URL against the base, the URL is returned unchanged when it is
+// empty or invalid.
+func resolveURL(base *url.URL, s string) string {
+    trimmed := strings.TrimSpace(s)
+    if trimmed == "" {
+        return s
+    }
+    ref, err := url.Parse(trimmed)
+    if err != nil {
+        return s
+    }
+    return base.ResolveReference(ref).String()
+}
+
+// Resolve each candidate URL of a srcset attribute, e.g.
+// "a.jpg 1x, b.jpg 2x", keeping the descriptors.
+func resolveSrcset(base *url.URL, s string) string {
+    candidates := strings.Split(s, ",")
+    for i, candidate := range candidates {
+        fields := strings.Fields(candidate)
+        if len(fields) == 0 {
+            continue
+        }
+        fields[0] = resolveURL(base, fields[0])
+        candidates[i] = strings.Join(fields, " ")
+    }
+    return strings.Join(candidates, ", ")
+}
+
+// Copy the nodes with the relative URLs of href, src, srcset, poster and
+// cite attributes resolved against the base, e.g. "../photos/x.jpg" or
+// "#post". The nodes themselves are not modified so that the document they
+// belong to can still be used.
+func AbsoluteURLs(nodes []*html.Node, base *url.URL) []*html.Node {
+    clones := make([]*html.Node, 0, len(nodes))
+    for _, node := range nodes {
+        clone := CloneNode(node)
+        WalkHtmlDoc(clone, func(wn *NodeWrapper, e WalkEvent) bool {
+            if e != WalkEnter || wn.Node.Type != html.ElementNode {
+                return true
+            }
+            for i, attr := range wn.Node.Attr {
+                if attr.Namespace != "" {
+                    continue
+                }
+                if attr.Key == "srcset" {
+                    wn.Node.Attr[i].Val = resolveSrcset(base, attr.Val)
+                } else if urlAttrs[attr.Key] {
+                    wn.Node.Attr[i].Val = resolveURL(base, attr.Val)
+                }
+            }
+            return true
+        })
+        clones = append(clones, clone)
+    }
+    return clones
+}
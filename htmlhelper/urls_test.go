@@ -0,0 +1,44 @@
+package htmlhelper
+
+import (
+    "golang.org/x/net/html"
+    "golang.org/x/net/html/atom"
+    "net/url"
+    "strings"
+    "testing"
+)
+
+// Testing that AbsoluteURLs resolves each URL attribute in a copy and leaves
+// the original nodes unchanged.
+func TestAbsoluteURLs(t *testing.T) {
+    src := `<p><a href="#other">other</a> <a href="https://example.com/">ext</a> <a href="mailto:a@b.c">mail</a></p>` +
+        `<img src="../photos/x.jpg" srcset="../photos/x.jpg 1x,  ../photos/x2.jpg 2x">` +
+        `<video poster="poster.png"><source src="/v.webm"></video>` +
+        `<blockquote cite="quotes/1"><p>quote</p></blockquote>`
+    body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+    nodes, err := html.ParseFragment(strings.NewReader(src), body)
+    if err != nil {
+        t.Fatalf("failed to parse fragment: %s", err)
+    }
+    base, _ := url.Parse("http://yarrie.net/microblog/#post")
+    render := func(nodes []*html.Node) string {
+        var b strings.Builder
+        for _, n := range nodes {
+            html.Render(&b, n)
+        }
+        return b.String()
+    }
+    before := render(nodes)
+
+    got := render(AbsoluteURLs(nodes, base))
+    expected := `<p><a href="http://yarrie.net/microblog/#other">other</a> <a href="https://example.com/">ext</a> <a href="mailto:a@b.c">mail</a></p>` +
+        `<img src="http://yarrie.net/photos/x.jpg" srcset="http://yarrie.net/photos/x.jpg 1x, http://yarrie.net/photos/x2.jpg 2x"/>` +
+        `<video poster="http://yarrie.net/microblog/poster.png"><source src="http://yarrie.net/v.webm"/></video>` +
+        `<blockquote cite="http://yarrie.net/microblog/quotes/1"><p>quote</p></blockquote>`
+    if got != expected {
+        t.Errorf("unexpected absolute urls\nexpected: %s\ngot:      %s", expected, got)
+    }
+    if after := render(nodes); after != before {
+        t.Errorf("expected the original nodes to be unchanged, got %s", after)
+    }
+}
@@ -4,14 +4,26 @@ package main
 import (
     "yarrienet/cli"
     "yarrienet/config"
+    "yarrienet/filehelper"
+    "yarrienet/htmlhelper"
     "yarrienet/microblog"
+    "yarrienet/rsshelper"
+    "golang.org/x/net/html"
+    "errors"
     "fmt"
+    "io/fs"
+    "net/url"
     "os"
     "path/filepath"
+    "strconv"
+    "strings"
+    "text/template"
     "time"
 )
 
 const defaultConfigPath = "~/.config/yarrienet.conf"
+const defaultBackupDir = "~/.cache/yarrienet/backups"
+const defaultBackupGenerations = 5
 const defaultBaseUrl = "http://yarrie.net/microblog"
 
 const usageInformation string = `USAGE
@@ -30,12 +42,129 @@ DESCRIPTION
   modified.
   
 COMMANDS
-  microblog new <microblog file> [-d | --date <YYYY-MM-DD-hh-mm-ss>]
-    Insert an empty post into the microblog HTML source code in place.
+  microblog new <microblog file> [-d | --date <date>] [--title <title>]
+                [--id-strategy <timestamp | random | slug>]
+                [--body <html> | --body-file <path> | -] [--markdown]
+                [--kind <note | photo | link | quote>] [--tags <tag,tag>]
+    Insert a post into the microblog HTML source code in place and print its ID. The body is taken
+    from --body, a file, or stdin with '-' (or '--body-file -'), otherwise the post is empty. With
+    --markdown the body is converted from Markdown to HTML. The ID is generated by the strategy
+    (default timestamp), slugs are derived from the title or body. Generated IDs never collide
+    with an existing ID in the document.
 
-  microblog genrss <microblog file> [<output rss>] [--url <base url>] 
+    The date defaults to now and is read in microblog_timezone (default local time). Accepted are
+    RFC3339 (2006-01-02T15:04:05+01:00), 2006-01-02 15:04[:05], 2006-01-02, 15:04[:05] for today,
+    now, today, yesterday or tomorrow optionally followed by a time ("yesterday 18:30"), or an
+    offset from now such as -2h, +30m, -1d12h or -1w.
+
+    The post is rendered by the template of its kind (default note). Templates can be replaced or
+    added with microblog_post_template in the config file, rendered posts are checked to match
+    the schema used to generate feeds.
+
+  microblog edit <id> [<microblog file>]
+    Open the body of a post (everything after its date) in $EDITOR and splice the result back into
+    the microblog HTML source code in place. The file is left untouched if the edited body is
+    empty or has unbalanced tags, the edit is then kept in a temporary file whose path is printed.
+
+  microblog delete <id> [<microblog file>] [--tombstone]
+    Remove a post from the microblog HTML source code in place. With --tombstone the post is
+    replaced by an empty <div class="post deleted"> marker keeping its ID, which is left out of
+    RSS feeds and marked as deleted by outputs that support it.
+
+  microblog list [<microblog file>] [--since <date>] [--until <date>] [--limit <n>]
+                 [--grep <regex>] [--json]
+    Print the ID, date and a plain text excerpt of each post. Dates take the forms of new --date, an
+    --until date without a time includes the whole day. The regex is matched against the ID and
+    plain text of each post.
+
+  microblog show <id> [<microblog file>]
+    Print a post as readable terminal text with links as footnotes.
+
+  microblog fmt [<microblog file>]
+    Rewrite the visible date of every post from its <time datetime> using the configured
+    microblog_date_layout, microblog_locale and microblog_timezone.
+
+  microblog undo
+    Restore the previous version of the files last changed by any command, e.g. a feed together
+    with its archives and tag feeds. Each file written is first backed up, see backup_dir and
+    backup_generations in the config file. Repeating undo steps further back.
+
+  microblog genrss <microblog file> [<output rss>] [--url <base url>] [--format <rss | atom | json>]
+                   [--feed-url <url>] [--legacy-description] [--limit <n>]
+                   [--since <date>] [--until <date>] [--no-sanitize] [--merge]
+                   [--validate] [--archive <year | n>] [--per-tag <dir>] [--tag-url <url>]
     Generate an RSS feed using the microblog file. Omitting output or using '-' will print the
-    generated RSS feed to stdout.
+    generated RSS feed to stdout. The HTML of each post is given in content:encoded with a plain
+    text summary as the description, --legacy-description (or microblog_feed_legacy_description)
+    instead puts the escaped HTML in the description as older versions did.
+
+    Posts are sorted newest first by date, with a warning when the document order disagrees as
+    that usually means a post is mis-dated. --limit keeps the newest n posts and --since and
+    --until (in the forms of new --date, an until date without a time includes the whole day)
+    restrict their dates, defaulting to microblog_feed_limit, microblog_feed_since and
+    microblog_feed_until.
+
+    With --format atom an Atom 1.0 feed is generated instead, its ID is the base url and deleted
+    posts are listed as tombstones. With --format json a JSON Feed 1.1 is generated, deleted posts
+    are listed by id outside of the items in "_yarrienet": {"deleted": [...]}. The feed links to
+    itself at --feed-url, by default the output file name relative to the base url.
+
+    The base url defaults to microblog_url and the feed metadata (title, author, language, ttl,
+    image, ...) is taken from the microblog_feed_* entries of the config file.
+
+    Images, audio and video in posts are looked up under site_root, relative to site_url, to add
+    an enclosure and media:content with their size, type and dimensions to RSS items and sizes to
+    JSON attachments. A warning is printed for each file missing locally.
+
+    Relative URLs in posts are resolved against the post permalink. Post HTML is reduced to an
+    allowlist of elements, attributes and URL schemes: scripts, styles, frames and forms are
+    dropped, other unknown elements are replaced by their content and attributes such as class, id
+    and on* handlers are removed, with a warning listing what was stripped from each post. The
+    microblog_rss_allow, microblog_atom_allow and microblog_json_allow entries extend the allowlist
+    of each format with rules such as "iframe", "iframe@src", "@class" or "magnet:".
+    --no-sanitize copies the HTML verbatim.
+
+    --merge reads the existing feed at the output path (or microblog_rss_file when printing to
+    stdout) in any format and keeps the ids and publication dates of its items, matching posts by
+    id or link or, when a post id was renamed, by identical content. Items whose content changed are
+    given an updated time of now. An unchanged feed is not written.
+
+    --validate checks the generated feed as feed validate does and prints the problems found,
+    the feed is not written when it has errors.
+
+    --archive (or microblog_feed_archive) keeps older posts in RFC 5005 archive feeds next to the
+    output file: one per year (rss-2024.xml) for every year before the newest post, or with a
+    number n pages of n posts counting from the oldest (rss-1.xml) for every full page. Posts of
+    pages already written stay in their page when deleted or re-dated, new pages only take the
+    other posts. The current feed has every post which is not archived whatever the limit, and
+    no archived post. Feeds link to each other with atom:link rel="current", "prev-archive" and
+    "next-archive" (next_url to the older archive in JSON Feed) and archives are marked with
+    fh:archive. Archives are only rewritten when their contents change.
+
+    Posts are tagged by a comma separated data-tags attribute and by rel="tag" links in their
+    content, named by the last segment of the link path (/tags/music is music). Tags are given as
+    category in RSS and Atom and tags in JSON Feed. --per-tag writes a feed of the posts of each
+    tag to the directory, named by the slug of the tag (music.xml), with an OPML index.opml of the
+    tags and their feed URLs. The feeds are published at --tag-url, by default the directory
+    relative to the feed url when it is below the output file. Unchanged tag feeds are not
+    rewritten. Tags only differing in case share a feed, other tags with the same slug are an
+    error. Feeds in the directory of tags no longer in use are left with a warning.
+
+  feed diff <old feed> <new feed> [--json]
+    Compare the items of two feeds by id and print the items added, removed, re-dated or changed
+    in title, link, content or categories, with a line diff of the plain text of changed content.
+    Either feed can be RSS 0.91-2.0, RSS 1.0, Atom or JSON Feed, '-' reads stdin. With --json the
+    differences are printed as a JSON object. Exits with 0 when the items are the same, 1 when
+    they differ and 2 on errors, like diff.
+
+  feed validate <feed> [--json]
+    Check a feed against the RSS 2.0, Atom 1.0 or JSON Feed 1.1 specification and print each
+    error and warning with the path of its element, e.g. rss/channel/item[2]/guid. Errors break
+    the specification: missing required elements, author values which are not email addresses,
+    guids which are not URLs without isPermaLink="false", invalid dates, duplicate ids and
+    relative links. Warnings are recommendations such as a missing item date or self link. '-'
+    reads stdin and --json prints a JSON array. Exits with 0 when valid, 1 on errors in the feed
+    and 2 when it cannot be read.
 
   help
     Print usage information.`
@@ -52,8 +181,19 @@ func printUsage() {
 // at the top of the microblog HTML page. Will parse additional CLI flags
 // and extras as part of the command. Returns a status code, success is 0.
 func cmdMicroblogNew() int {
+    // a '-' argument reads the post body from stdin rather than being a path
+    var args []string
+    var stdinBody = false
+    for _, a := range c.Arguments {
+        if a == "-" {
+            stdinBody = true
+        } else {
+            args = append(args, a)
+        }
+    }
+
     // check if extra arguments were provided, and error
-    if len(c.Arguments) > 1 {
+    if len(args) > 1 {
         fmt.Fprintf(os.Stderr, "[error] more than one argument provided\n")
         return 1
     }
@@ -65,54 +205,106 @@ func cmdMicroblogNew() int {
     if conf != nil {
         htmlPath = conf.MicroblogHtmlFile
     }
-    if len(c.Arguments) == 1 {
-        htmlPath = c.Arguments[0]
+    if len(args) == 1 {
+        htmlPath = args[0]
     } else if htmlPath == "" {
         fmt.Fprintf(os.Stderr, "[error] missing html path\n")
         return 1
     }
     htmlPath = resolvePath(htmlPath)
 
-    // open file for writing
-    f, err := os.OpenFile(htmlPath, os.O_RDWR, 0644)
+    // read the post body before the html file, may come from stdin
+    body, err := readPostBody(stdinBody)
     if err != nil {
-        fmt.Fprintf(os.Stderr, "[error] failed to open html file: %s\n", htmlPath)
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 1
+    }
+
+    src, err := os.ReadFile(htmlPath)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to read html file: %s\n", htmlPath)
         return 1
     }
-    defer f.Close()
 
-    // format datetime (if present)
-    var datetime time.Time
-    var datetimeStr string
-    // parse date defined in -d or --date
-    if v, ok := c.Flags["d"]; ok {
-        datetimeStr = v    
-    } else if v, ok := c.Flags["date"]; ok {
-        datetimeStr = v    
-    }
-    if datetimeStr != "" {
-        // YYYY-MM-DD-hh:mm:ss
-        datetime, err = time.Parse("2006-01-02-15:04:05", datetimeStr)
+    dateFormat, err := microblogDateFormat()
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 1
+    }
+
+    // parse date defined in -d or --date, read in the configured timezone.
+    // if date not provided then use current. has the side effect of a date
+    // flag with a missing value will just produce current date.
+    var datetime = time.Now()
+    if v, ok := c.Flag("d", "date"); ok && v != "" {
+        datetime, err = microblog.ParseDate(v, time.Now(), dateFormat.Location)
         if err != nil {
-            fmt.Fprintf(os.Stderr, "[error] invalid date provided: %s\n", err)
+            fmt.Fprintf(os.Stderr, "[error] %s\n", err)
             return 1
         }
-    } else {
-        // if date not provided then use current. has the side effect of a date
-        // flag with a missing value will just produce current date.
-        datetime = time.Now()
     }
-    // when parsing the date convert to current timezone to achieve +0100
-    // in <time datetime> -- though this does not work? time.Now() does this
-    // automatically
-    //
-    // datetime = datetime.In(time.Local)
 
-    err = microblog.InsertNewPostFile(f, datetime)
+    // determine id strategy, flag supersedes config file entry
+    var strategyStr string
+    if conf != nil {
+        strategyStr = conf.MicroblogIDStrategy
+    }
+    if v, ok := c.Flag("id-strategy"); ok {
+        if len(v) == 0 {
+            fmt.Fprintf(os.Stderr, "[error] id strategy flag missing value\n")
+            return 1
+        }
+        strategyStr = v
+    }
+    strategy, err := microblog.ParseIDStrategy(strategyStr)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 1
+    }
+    title, _ := c.Flag("title")
+
+    // comma separated tags, empty tags are ignored
+    var tags []string
+    if v, ok := c.Flag("tags"); ok {
+        for _, tag := range strings.Split(v, ",") {
+            if tag = strings.TrimSpace(tag); tag != "" {
+                tags = append(tags, tag)
+            }
+        }
+    }
+
+    // load the user's post templates, otherwise the built-in templates are
+    // used
+    var templates *template.Template
+    if conf != nil && conf.MicroblogPostTemplate != "" {
+        templates, err = microblog.LoadTemplates(resolvePath(conf.MicroblogPostTemplate))
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to load post template: %s\n", err)
+            return 1
+        }
+    }
+    kind, _ := c.Flag("kind")
+
+    src, id, err := microblog.InsertNewPost(src, microblog.NewPost{
+        Datetime: datetime,
+        Title: title,
+        Tags: tags,
+        Body: body,
+        IDStrategy: strategy,
+        Templates: templates,
+        Kind: kind,
+        DateFormat: dateFormat,
+    })
     if err != nil {
         fmt.Fprintf(os.Stderr, "[error] failed to insert new post: %s\n", err)
         return 1
     }
+    if err = writeFile(htmlPath, src); err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to write html file: %s\n", err)
+        return 1
+    }
+    // print the id so it can be used by scripts
+    fmt.Println(id)
     return 0
 }
 
@@ -160,8 +352,11 @@ func cmdMicroblogGenrss() int {
         outputPath = ""
     }
 
-    // get the base url
+    // get the base url, flag supersedes config file entry
     var baseUrl string = defaultBaseUrl
+    if conf != nil && conf.MicroblogUrl != "" {
+        baseUrl = conf.MicroblogUrl
+    }
     if baseUrlFlag, ok := c.Flags["url"]; ok {
         if len(baseUrlFlag) > 0 {
             baseUrl = baseUrlFlag
@@ -170,11 +365,122 @@ func cmdMicroblogGenrss() int {
             return 1
         }
     }
-    metadata := &microblog.RSSMetadata{
-        Title: "yarrie",
-        Author: "yarrie",
-        Description: "yarrie's microblog",
-        BaseUrl: baseUrl, 
+    // feed format, rss by default
+    var format = "rss"
+    if v, ok := c.Flags["format"]; ok {
+        format = v
+    }
+    if format != "rss" && format != "atom" && format != "json" {
+        fmt.Fprintf(os.Stderr, "[error] unknown feed format '%s' (expected rss, atom or json)\n", format)
+        return 1
+    }
+
+    // url of the feed itself, by default the output file name relative to
+    // the base url
+    var feedUrl string
+    if v, ok := c.Flags["feed-url"]; ok && v != "" {
+        feedUrl = v
+    } else if conf != nil && conf.MicroblogFeedUrl != "" {
+        feedUrl = conf.MicroblogFeedUrl
+    } else if outputPath != "" {
+        feedUrl = strings.TrimSuffix(baseUrl, "/") + "/" + filepath.Base(outputPath)
+    }
+
+    metadata, err := feedMetadata(baseUrl, feedUrl)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 1
+    }
+    // flag supersedes config file entry
+    if _, ok := c.Flags["legacy-description"]; ok {
+        metadata.LegacyDescription = true
+    }
+    if metadata.Window, err = feedWindow(); err != nil {
+        fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+        return 1
+    }
+    if _, ok := c.Flags["no-sanitize"]; !ok {
+        if metadata.Sanitize, err = sanitizePolicy(format); err != nil {
+            fmt.Fprintf(os.Stderr, "[error] %s\n", err)
+            return 1
+        }
+    }
+
+    // merge with the existing feed, which is the output file or the config
+    // file entry when printing to stdout
+    _, merge := c.Flags["merge"]
+    var previousData []byte
+    if merge {
+        mergePath := outputPath
+        if mergePath == "" && conf != nil {
+            mergePath = resolvePath(conf.MicroblogRssFile)
+        }
+        if mergePath == "" {
+            fmt.Fprintf(os.Stderr, "[error] --merge requires an output file or microblog_rss_file\n")
+            return 1
+        }
+        previousData, err = os.ReadFile(mergePath)
+        if errors.Is(err, fs.ErrNotExist) {
+            fmt.Fprintf(os.Stderr, "[warning] no existing feed at %s to merge, generating from scratch\n", mergePath)
+        } else if err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to read existing feed: %s\n", err)
+            return 1
+        } else {
+            previous, err := rsshelper.Decode(previousData)
+            if err != nil {
+                fmt.Fprintf(os.Stderr, "[error] failed to decode existing feed %s: %s\n", mergePath, err)
+                return 1
+            }
+            metadata.Merge = microblog.NewFeedMerge(previous, time.Now().Truncate(time.Second))
+        }
+    }
+
+    // archive older posts (RFC 5005), flag supersedes config file entry
+    var archive string
+    if conf != nil {
+        archive = conf.MicroblogFeedArchive
+    }
+    if v, ok := c.Flag("archive"); ok {
+        archive = v
+    }
+    var archiveSize int
+    if archive != "" && archive != "year" {
+        if archiveSize, err = strconv.Atoi(archive); err != nil || archiveSize < 1 {
+            fmt.Fprintf(os.Stderr, "[error] archive expects \"year\" or a positive integer\n")
+            return 1
+        }
+    }
+    if archive != "" && outputPath == "" {
+        fmt.Fprintf(os.Stderr, "[error] --archive requires an output file\n")
+        return 1
+    }
+    if archive != "" && feedUrl == "" {
+        fmt.Fprintf(os.Stderr, "[error] --archive requires a feed url\n")
+        return 1
+    }
+
+    // feeds per tag, the url of their directory defaults to its path
+    // relative to the output file
+    tagDir, _ := c.Flag("per-tag")
+    tagDir = resolvePath(tagDir)
+    tagUrl, _ := c.Flag("tag-url")
+    var tagExt string
+    if tagDir != "" {
+        if tagUrl == "" && outputPath != "" && feedUrl != "" {
+            if rel, err := filepath.Rel(filepath.Dir(outputPath), tagDir); err == nil && !strings.HasPrefix(rel, "..") {
+                tagUrl = archiveFeedUrl(feedUrl, filepath.ToSlash(rel))
+            }
+        }
+        if tagUrl == "" {
+            fmt.Fprintf(os.Stderr, "[error] --per-tag requires --tag-url when the directory is not below the output file\n")
+            return 1
+        }
+        tagExt = filepath.Ext(outputPath)
+        if tagExt == "" && format == "json" {
+            tagExt = ".json"
+        } else if tagExt == "" {
+            tagExt = ".xml"
+        }
     }
 
     // open the html file
@@ -185,13 +491,90 @@ func cmdMicroblogGenrss() int {
     }
     defer f.Close()
 
-    // generate the final rss feed, returns a string containing feed
-    s, err := microblog.GenRssFromFile(f, metadata)
+    doc, err := html.Parse(f)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to parse microblog file: %s\n", err)
+        return 1
+    }
+
+    // generate the final feed, returns a string containing feed
+    var s string
+    var archives []microblog.FeedArchive
+    switch {
+    case archive != "":
+        archiveUrl := func(key string) string {
+            return archiveFeedUrl(feedUrl, filepath.Base(archiveFeedPath(outputPath, key)))
+        }
+        s, archives, err = microblog.GenArchived(doc, metadata, format, archiveSize, archiveUrl, archivedPages(outputPath, archiveSize))
+    case format == "atom":
+        s, err = microblog.GenAtom(doc, metadata)
+    case format == "json":
+        s, err = microblog.GenJSONFeed(doc, metadata)
+    default:
+        s, err = microblog.GenRss(doc, metadata)
+    }
     if err != nil {
-        fmt.Fprintf(os.Stderr, "[error] failed to generate rss: %s\n", err)
+        fmt.Fprintf(os.Stderr, "[error] failed to generate %s: %s\n", format, err)
         return 1
     }
 
+    // a feed per tag in the directory with an index of the tags
+    var tagFeeds []microblog.TagFeed
+    var tagIndex string
+    if tagDir != "" {
+        tagFeeds, err = microblog.GenTagFeeds(doc, metadata, format, func(slug string) string {
+            return strings.TrimSuffix(tagUrl, "/") + "/" + slug + tagExt
+        })
+        if err == nil {
+            tagIndex, err = microblog.GenTagIndex(tagFeeds, metadata)
+        }
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to generate tag feeds: %s\n", err)
+            return 1
+        }
+    }
+
+    // validate the generated feeds, which are not written when invalid
+    if _, ok := c.Flags["validate"]; ok {
+        valid := validateGenerated(format, s)
+        for _, a := range archives {
+            valid = validateGenerated(format + " archive " + a.Key, a.Data) && valid
+        }
+        for _, t := range tagFeeds {
+            valid = validateGenerated(format + " of tag " + t.Tag, t.Data) && valid
+        }
+        if !valid {
+            return 1
+        }
+    }
+
+    // archives are only rewritten when their contents change
+    for _, a := range archives {
+        archivePath := archiveFeedPath(outputPath, a.Key)
+        if err = writeFileIfChanged(archivePath, []byte(a.Data)); err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to write archive %s: %s\n", archivePath, err)
+            return 1
+        }
+    }
+
+    if tagDir != "" {
+        if err = os.MkdirAll(tagDir, 0755); err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to create tag directory: %s\n", err)
+            return 1
+        }
+        for _, t := range tagFeeds {
+            if err = writeFileIfChanged(filepath.Join(tagDir, t.Slug + tagExt), []byte(t.Data)); err != nil {
+                fmt.Fprintf(os.Stderr, "[error] failed to write feed of tag %s: %s\n", t.Tag, err)
+                return 1
+            }
+        }
+        if err = writeFileIfChanged(filepath.Join(tagDir, "index.opml"), []byte(tagIndex)); err != nil {
+            fmt.Fprintf(os.Stderr, "[error] failed to write tag index: %s\n", err)
+            return 1
+        }
+        warnStaleTagFeeds(tagDir, tagExt, tagFeeds)
+    }
+
     // default behavior for missing output path is print to stdout
     if outputPath == "" {
         // print and exit with success
@@ -199,16 +582,14 @@ func cmdMicroblogGenrss() int {
         return 0
     }
 
-    // open or create the provided output path
-    outputFile, err := os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
-    if err != nil {
-        fmt.Fprintf(os.Stderr, "[error] failed to open output file: %s\n", err)
-        return 1
+    // an unchanged merged feed is not written
+    if merge && previousData != nil && string(previousData) == s {
+        fmt.Fprintf(os.Stderr, "%s unchanged\n", outputPath)
+        return 0
     }
-    defer outputFile.Close()
 
-    // write the string to the file
-    _, err = outputFile.WriteString(s)
+    // write the string to the file, replacing any existing file
+    err = writeFile(outputPath, []byte(s))
     if err != nil {
         fmt.Fprintf(os.Stderr, "[error] failed to write generated rss to output file: %s\n", err)
         return 1
@@ -216,6 +597,231 @@ func cmdMicroblogGenrss() int {
     return 0
 }
 
+// Warn about each feed in the tag directory which is not the feed of a tag in
+// use, e.g. of a tag no longer given to any post. They are left in place as
+// the directory may hold other files.
+func warnStaleTagFeeds(tagDir string, tagExt string, tagFeeds []microblog.TagFeed) {
+    entries, err := os.ReadDir(tagDir)
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[warning] failed to list tag directory: %s\n", err)
+        return
+    }
+    current := map[string]bool{}
+    for _, t := range tagFeeds {
+        current[t.Slug + tagExt] = true
+    }
+    for _, entry := range entries {
+        name := entry.Name()
+        if entry.IsDir() || filepath.Ext(name) != tagExt || current[name] {
+            continue
+        }
+        fmt.Fprintf(os.Stderr, "[warning] %s is not the feed of a tag in use, remove it if the tag is gone\n", filepath.Join(tagDir, name))
+    }
+}
+
+// Write a file unless it already has the data, so that unchanged feeds keep
+// their modification time.
+func writeFileIfChanged(path string, data []byte) error {
+    if existing, err := os.ReadFile(path); err == nil && string(existing) == string(data) {
+        return nil
+    }
+    return writeFile(path, data)
+}
+
+// Validate a generated feed, printing its problems. Returns whether the feed
+// has no errors.
+func validateGenerated(name string, s string) bool {
+    problems, err := rsshelper.Validate([]byte(s))
+    if err != nil {
+        fmt.Fprintf(os.Stderr, "[error] failed to validate generated %s: %s\n", name, err)
+        return false
+    }
+    for _, p := range problems {
+        fmt.Fprintf(os.Stderr, "[%s] %s: %s\n", p.Severity, p.Path, p.Message)
+    }
+    if rsshelper.HasErrors(problems) {
+        fmt.Fprintf(os.Stderr, "[error] generated %s is invalid, not written\n", name)
+        return false
+    }
+    return true
+}
+
+// Path of an archive of the feed at the output path, the key appended to its
+// name, e.g. rss.xml and 2024 give rss-2024.xml.
+func archiveFeedPath(outputPath string, key string) string {
+    ext := filepath.Ext(outputPath)
+    return strings.TrimSuffix(outputPath, ext) + "-" + key + ext
+}
+
+// IDs and links of the items of each archive page already written next to
+// the feed at the output path, page 1 first, so that posts keep their page.
+// Pages are read until one is missing, none in year mode.
+func archivedPages(outputPath string, size int) [][]string {
+    var pages [][]string
+    for page := 1; size > 0; page++ {
+        path := archiveFeedPath(outputPath, strconv.Itoa(page))
+        data, err := os.ReadFile(path)
+        if err != nil {
+            if !os.IsNotExist(err) {
+                fmt.Fprintf(os.Stderr, "[warning] failed to read archive %s, its posts may move to another page: %s\n", path, err)
+            }
+            break
+        }
+        feed, err := rsshelper.Decode(data)
+        if err != nil {
+            fmt.Fprintf(os.Stderr, "[warning] failed to decode archive %s, its posts may move to another page: %s\n", path, err)
+            break
+        }
+        var items []string
+        for _, item := range feed.Items {
+            items = append(items, item.ID)
+            if item.Link != "" {
+                items = append(items, item.Link)
+            }
+        }
+        pages = append(pages, items)
+    }
+    return pages
+}
+
+// URL of an archive or directory published relative to the feed at feedUrl.
+func archiveFeedUrl(feedUrl string, relPath string) string {
+    u, err := url.Parse(feedUrl)
+    if err != nil {
+        return feedUrl
+    }
+    return u.ResolveReference(&url.URL{Path: relPath}).String()
+}
+
+// Split a comma separated config value, empty values are dropped.
+func splitList(s string) []string {
+    var list []string
+    for _, v := range strings.Split(s, ",") {
+        if v = strings.TrimSpace(v); v != "" {
+            list = append(list, v)
+        }
+    }
+    return list
+}
+
+// Feed metadata from the microblog_feed_* config file entries, with the
+// original title, author and description as defaults. Returns an error when
+// a config value is invalid.
+func feedMetadata(baseUrl string, feedUrl string) (*microblog.RSSMetadata, error) {
+    metadata := &microblog.RSSMetadata{
+        Title: "yarrie",
+        Author: "yarrie",
+        Description: "yarrie's microblog",
+        BaseUrl: baseUrl, 
+        FeedUrl: feedUrl,
+        // the feeds of a run print each warning once
+        Warnings: &microblog.FeedWarnings{},
+    }
+    if conf == nil {
+        return metadata, nil
+    }
+    if conf.MicroblogFeedTitle != "" {
+        metadata.Title = conf.MicroblogFeedTitle
+    }
+    if conf.MicroblogFeedAuthor != "" {
+        metadata.Author = conf.MicroblogFeedAuthor
+    }
+    if conf.MicroblogFeedDescription != "" {
+        metadata.Description = conf.MicroblogFeedDescription
+    }
+    metadata.Language = conf.MicroblogFeedLanguage
+    metadata.TitleLength = conf.MicroblogTitleLength
+    metadata.SummaryLength = conf.MicroblogSummaryLength
+    metadata.Copyright = conf.MicroblogFeedCopyright
+    metadata.ManagingEditor = conf.MicroblogFeedManagingEditor
+    metadata.WebMaster = conf.MicroblogFeedWebMaster
+    metadata.Generator = conf.MicroblogFeedGenerator
+    metadata.Docs = conf.MicroblogFeedDocs
+    metadata.TTL = conf.MicroblogFeedTTL
+    metadata.ImageUrl = conf.MicroblogFeedImage
+    metadata.LegacyDescription = conf.MicroblogFeedLegacyDescription
+    metadata.SiteUrl = conf.SiteUrl
+    metadata.SiteRoot = resolvePath(conf.SiteRoot)
+    metadata.Categories = splitList(conf.MicroblogFeedCategories)
+    metadata.SkipDays = splitList(conf.MicroblogFeedSkipDays)
+    for _, v := range splitList(conf.MicroblogFeedSkipHours) {
+        hour, err := strconv.Atoi(v)
+        if err != nil || hour < 0 || hour > 23 {
+            return nil, fmt.Errorf("invalid hour '%s' in microblog_feed_skip_hours (expected 0-23)", v)
+        }
+        metadata.SkipHours = append(metadata.SkipHours, hour)
+    }
+    return metadata, nil
+}
+
+// Allowlist of the HTML of posts in a generated feed of the format, the
+// default policy extended by the microblog_<format>_allow config file entry.
+// Returns an error when a rule is invalid.
+func sanitizePolicy(format string) (*htmlhelper.SanitizePolicy, error) {
+    policy := htmlhelper.NewSanitizePolicy()
+    if conf == nil {
+        return policy, nil
+    }
+    var rules string
+    switch format {
+    case "atom":
+        rules = conf.MicroblogAtomAllow
+    case "json":
+        rules = conf.MicroblogJsonAllow
+    default:
+        rules = conf.MicroblogRssAllow
+    }
+    for _, rule := range splitList(rules) {
+        if err := policy.Allow(rule); err != nil {
+            return nil, fmt.Errorf("%s in microblog_%s_allow", err, format)
+        }
+    }
+    return policy, nil
+}
+
+// Posts included in a generated feed from the --limit, --since and --until
+// flags, which supersede the microblog_feed_limit, microblog_feed_since and
+// microblog_feed_until config file entries. Returns an error when a value is
+// invalid.
+func feedWindow() (microblog.Filter, error) {
+    var window microblog.Filter
+    var limit, since, until string
+    if conf != nil {
+        if conf.MicroblogFeedLimit != 0 {
+            limit = strconv.Itoa(conf.MicroblogFeedLimit)
+        }
+        since = conf.MicroblogFeedSince
+        until = conf.MicroblogFeedUntil
+    }
+    if v, ok := c.Flag("limit"); ok {
+        limit = v
+    }
+    if v, ok := c.Flag("since"); ok {
+        since = v
+    }
+    if v, ok := c.Flag("until"); ok {
+        until = v
+    }
+
+    var err error
+    if limit != "" {
+        if window.Limit, err = strconv.Atoi(limit); err != nil || window.Limit < 1 {
+            return window, fmt.Errorf("feed limit expects a positive integer")
+        }
+    }
+    if since != "" {
+        if window.Since, err = parseDateFlag(since); err != nil {
+            return window, fmt.Errorf("feed since: %s", err)
+        }
+    }
+    if until != "" {
+        if window.Until, err = parseDateEndFlag(until); err != nil {
+            return window, fmt.Errorf("feed until: %s", err)
+        }
+    }
+    return window, nil
+}
+
 // Takes an absolute path and resolves it by replacing any `~` character at
 // the start of the path with the user's home directory. Safe to pass an empty
 // string to return an empty string. Returns the resolved path.
@@ -233,9 +839,50 @@ func resolvePath(path string) string {
     return path
 }
 
+// Backups of the files written by the command, created by backups.
+var commandBackups *filehelper.Backups
+
+// Backups used when writing files, configured by backup_dir and
+// backup_generations in the config file. Every file written by the command
+// shares the same backups so that undo restores them together. Returns nil
+// when backups are disabled.
+func backups() *filehelper.Backups {
+    if commandBackups != nil {
+        return commandBackups
+    }
+    var dir = defaultBackupDir
+    var generations = defaultBackupGenerations
+    if conf != nil {
+        if conf.BackupDir != "" {
+            dir = conf.BackupDir
+        }
+        if conf.BackupGenerations < 0 {
+            return nil
+        } else if conf.BackupGenerations > 0 {
+            generations = conf.BackupGenerations
+        }
+    }
+    commandBackups = &filehelper.Backups{
+        Dir: resolvePath(dir),
+        Generations: generations,
+    }
+    return commandBackups
+}
+
+// Write the data to the file at the path atomically, backing up the previous
+// version of the file. Returns an error on failure, the file is untouched.
+func writeFile(path string, data []byte) error {
+    return filehelper.WriteFile(path, data, backups())
+}
+
 // CLI and config are parsed before command branching.
 var c *cli.CLI
 var conf *config.Config
+
+// Flags which never take a value, the word following one is parsed as
+// an argument, e.g. old.xml in 'feed diff --json old.xml new.xml'.
+var boolFlags = []string{"h", "help", "json", "merge", "validate", "no-sanitize", "tombstone", "legacy-description"}
+
 func main() {
     // parse cli using helper function which breaks cli args into commmand,
     // subcommand, flags, and extra strings
@@ -245,7 +892,7 @@ func main() {
     // currently all erroring for extraneous arguments and flags are left to
     // the command branches to handle. no extraneous flag checks are completed
     // as there is no command logic beyond the switch statement below.
-    c = cli.Parse()
+    c = cli.Parse(boolFlags...)
     if c == nil {
         fmt.Fprintf(os.Stderr, "[error] failed to parse command line arguments\n")
         return
@@ -314,6 +961,24 @@ func main() {
             case "new":
                 s := cmdMicroblogNew() 
                 os.Exit(s)
+            case "edit":
+                s := cmdMicroblogEdit()
+                os.Exit(s)
+            case "delete":
+                s := cmdMicroblogDelete()
+                os.Exit(s)
+            case "list":
+                s := cmdMicroblogList()
+                os.Exit(s)
+            case "show":
+                s := cmdMicroblogShow()
+                os.Exit(s)
+            case "fmt":
+                s := cmdMicroblogFmt()
+                os.Exit(s)
+            case "undo":
+                s := cmdMicroblogUndo()
+                os.Exit(s)
             case "genrss":
                 s := cmdMicroblogGenrss()
                 os.Exit(s)
@@ -321,6 +986,22 @@ func main() {
                 fmt.Fprintf(os.Stderr, "[error] unknown microblog subcommand '%s'\n", c.Subcommand)
                 os.Exit(1)
         }
+    case "feed":
+        if c.Subcommand == "" {
+            fmt.Fprintf(os.Stderr, "[error] feed requires a subcommand\n")
+            os.Exit(2)
+        }
+        switch c.Subcommand {
+            case "diff":
+                s := cmdFeedDiff()
+                os.Exit(s)
+            case "validate":
+                s := cmdFeedValidate()
+                os.Exit(s)
+            default:
+                fmt.Fprintf(os.Stderr, "[error] unknown feed subcommand '%s'\n", c.Subcommand)
+                os.Exit(2)
+        }
     default:
         fmt.Fprintf(os.Stderr, "[error] unknown command '%s'\n", c.Command)
         os.Exit(1)
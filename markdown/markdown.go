@@ -0,0 +1,219 @@
+// Package markdown converts a small subset of Markdown to HTML.
+//
+// Supported are paragraphs, emphasis (*em*, **strong**), links, images,
+// inline code and unordered and ordered lists. Raw HTML is not passed
+// through, any HTML special characters are escaped.
+package markdown
+
+import (
+    "html"
+    "regexp"
+    "strings"
+    "unicode"
+)
+
+// Kind of block a line belongs to.
+type blockKind int
+const (
+    blockParagraph blockKind = iota
+    blockUnordered
+    blockOrdered
+)
+
+// Matches the marker of a list item line and captures the content.
+var unorderedItemRe = regexp.MustCompile(`^\s{0,3}[-*+]\s+(.*)$`)
+var orderedItemRe = regexp.MustCompile(`^\s{0,3}\d+[.)]\s+(.*)$`)
+
+// A block being built from consecutive lines.
+type block struct {
+    kind blockKind
+    // lines of a paragraph, or each item of a list
+    lines []string
+}
+
+// Convert the Markdown source to HTML. Blocks are separated by new lines.
+func ToHTML(src string) string {
+    var blocks []*block
+    var current *block
+    for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+        if strings.TrimSpace(line) == "" {
+            // blank line ends the current block
+            current = nil
+            continue
+        }
+
+        var kind = blockParagraph
+        var content = strings.TrimSpace(line)
+        if m := unorderedItemRe.FindStringSubmatch(line); m != nil {
+            kind, content = blockUnordered, m[1]
+        } else if m := orderedItemRe.FindStringSubmatch(line); m != nil {
+            kind, content = blockOrdered, m[1]
+        }
+
+        switch {
+        case kind == blockParagraph && current != nil && current.kind != blockParagraph:
+            // non-item line following an item continues the item
+            current.lines[len(current.lines)-1] += " " + content
+        case kind == blockParagraph && current != nil:
+            current.lines = append(current.lines, content)
+        case kind != blockParagraph && current != nil && current.kind == kind:
+            current.lines = append(current.lines, content)
+        default:
+            current = &block{kind: kind, lines: []string{content}}
+            blocks = append(blocks, current)
+        }
+    }
+
+    var out []string
+    for _, b := range blocks {
+        switch b.kind {
+        case blockParagraph:
+            var lines []string
+            for _, l := range b.lines {
+                lines = append(lines, inline(l))
+            }
+            out = append(out, "<p>" + strings.Join(lines, "\n") + "</p>")
+        case blockUnordered, blockOrdered:
+            tag := "ul"
+            if b.kind == blockOrdered {
+                tag = "ol"
+            }
+            out = append(out, "<" + tag + ">")
+            for _, l := range b.lines {
+                out = append(out, "    <li>" + inline(l) + "</li>")
+            }
+            out = append(out, "</" + tag + ">")
+        }
+    }
+    return strings.Join(out, "\n")
+}
+
+// Convert the inline elements of a line of text to HTML.
+func inline(s string) string {
+    var sb strings.Builder
+    r := []rune(s)
+    for i := 0; i < len(r); i++ {
+        switch c := r[i]; {
+        case c == '\\' && i + 1 < len(r) && (unicode.IsPunct(r[i+1]) || unicode.IsSymbol(r[i+1])):
+            // escaped punctuation is written literally
+            i++
+            sb.WriteString(html.EscapeString(string(r[i])))
+        case c == '`':
+            if end := indexRune(r, '`', i + 1); end > 0 {
+                sb.WriteString("<code>" + html.EscapeString(string(r[i+1:end])) + "</code>")
+                i = end
+                continue
+            }
+            sb.WriteString("`")
+        case c == '!' && i + 1 < len(r) && r[i+1] == '[':
+            if text, url, end := parseLink(r, i + 1); end > 0 {
+                sb.WriteString(`<img src="` + html.EscapeString(url) + `" alt="` + html.EscapeString(text) + `">`)
+                i = end
+                continue
+            }
+            sb.WriteString("!")
+        case c == '[':
+            if text, url, end := parseLink(r, i); end > 0 {
+                sb.WriteString(`<a href="` + html.EscapeString(url) + `">` + inline(text) + `</a>`)
+                i = end
+                continue
+            }
+            sb.WriteString("[")
+        case c == '*' || c == '_':
+            // underscores within words are not emphasis, e.g. snake_case
+            if c == '_' && i > 0 && isWordRune(r[i-1]) {
+                sb.WriteRune(c)
+                continue
+            }
+            delim := string(c)
+            if i + 1 < len(r) && r[i+1] == c {
+                delim += string(c)
+            }
+            if end := indexDelim(r, delim, i + len(delim)); end > 0 {
+                tag := "em"
+                if len(delim) == 2 {
+                    tag = "strong"
+                }
+                sb.WriteString("<" + tag + ">" + inline(string(r[i+len(delim):end])) + "</" + tag + ">")
+                i = end + len(delim) - 1
+                continue
+            }
+            sb.WriteString(delim)
+            i += len(delim) - 1
+        default:
+            sb.WriteString(html.EscapeString(string(c)))
+        }
+    }
+    return sb.String()
+}
+
+// Parse a link in the form [text](url) starting at the opening bracket.
+// Returns the text, url and index of the closing parenthesis, an end of -1
+// when the runes do not form a link.
+func parseLink(r []rune, start int) (string, string, int) {
+    // find the closing bracket, allowing nested brackets in the text
+    depth := 0
+    closeBracket := -1
+    for i := start; i < len(r); i++ {
+        if r[i] == '[' {
+            depth++
+        } else if r[i] == ']' {
+            depth--
+            if depth == 0 {
+                closeBracket = i
+                break
+            }
+        }
+    }
+    if closeBracket < 0 || closeBracket + 1 >= len(r) || r[closeBracket+1] != '(' {
+        return "", "", -1
+    }
+    closeParen := indexRune(r, ')', closeBracket + 2)
+    if closeParen < 0 {
+        return "", "", -1
+    }
+    url := strings.TrimSpace(string(r[closeBracket+2:closeParen]))
+    if url == "" || strings.ContainsAny(url, " \t") {
+        return "", "", -1
+    }
+    return string(r[start+1:closeBracket]), url, closeParen
+}
+
+// Index of the rune at or after start, -1 when missing.
+func indexRune(r []rune, c rune, start int) int {
+    for i := start; i < len(r); i++ {
+        if r[i] == c {
+            return i
+        }
+    }
+    return -1
+}
+
+// Index of the closing emphasis delimiter at or after start. The delimiter
+// must follow non-space content, -1 when missing.
+func indexDelim(r []rune, delim string, start int) int {
+    d := []rune(delim)
+    if start >= len(r) || unicode.IsSpace(r[start]) {
+        return -1
+    }
+    for i := start + 1; i + len(d) <= len(r); i++ {
+        if string(r[i:i+len(d)]) != delim || unicode.IsSpace(r[i-1]) {
+            continue
+        }
+        // a single delimiter must not be part of a double, e.g. "*a **b**"
+        if len(d) == 1 && i + 1 < len(r) && r[i+1] == d[0] {
+            i++
+            continue
+        }
+        // closing underscores must not be followed by a word, e.g. snake_case
+        if d[0] == '_' && i + len(d) < len(r) && isWordRune(r[i+len(d)]) {
+            continue
+        }
+        return i
+    }
+    return -1
+}
+
+func isWordRune(r rune) bool {
+    return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,55 @@
+package markdown
+
+import (
+    "testing"
+)
+
+// Test converting each supported inline element with ToHTML.
+func TestInline(t *testing.T) {
+    var cases = map[string]string{
+        "plain text": "<p>plain text</p>",
+        "*em* and _em_": "<p><em>em</em> and <em>em</em></p>",
+        "**strong** and __strong__": "<p><strong>strong</strong> and <strong>strong</strong></p>",
+        "a [link](http://yarrie.net) here": `<p>a <a href="http://yarrie.net">link</a> here</p>`,
+        "[*em* link](/x)": `<p><a href="/x"><em>em</em> link</a></p>`,
+        "![a cat](cat.jpg)": `<p><img src="cat.jpg" alt="a cat"></p>`,
+        "`<code> *x*`": "<p><code>&lt;code&gt; *x*</code></p>",
+        "snake_case_name": "<p>snake_case_name</p>",
+        "2 * 3 * 4": "<p>2 * 3 * 4</p>",
+        `\*not em\*`: "<p>*not em*</p>",
+        "<b>raw</b> & more": "<p>&lt;b&gt;raw&lt;/b&gt; &amp; more</p>",
+        "[not a link] (x)": "<p>[not a link] (x)</p>",
+    }
+    for input, expected := range cases {
+        if out := ToHTML(input); out != expected {
+            t.Errorf("converting '%s' expected '%s' not '%s'", input, expected, out)
+        }
+    }
+}
+
+// Test converting paragraphs and lists with ToHTML.
+func TestBlocks(t *testing.T) {
+    input := `first paragraph
+continues here
+
+- one
+- two
+  continued
+
+1. first
+2. second
+third paragraph`
+    expected := `<p>first paragraph
+continues here</p>
+<ul>
+    <li>one</li>
+    <li>two continued</li>
+</ul>
+<ol>
+    <li>first</li>
+    <li>second third paragraph</li>
+</ol>`
+    if out := ToHTML(input); out != expected {
+        t.Errorf("expected:\n%s\nnot:\n%s", expected, out)
+    }
+}
@@ -0,0 +1,233 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "golang.org/x/net/html"
+    "fmt"
+    "slices"
+    "strconv"
+)
+
+// RFC 5005 links of a feed document, omitted when empty.
+type FeedHistory struct {
+    // URL of the current feed, only set in archive documents which are
+    // then marked with fh:archive.
+    Current string
+    // URLs of the archive documents of the previous (older) and next
+    // (newer) posts.
+    PrevArchive string
+    NextArchive string
+}
+
+// Whether the feed document is an archive.
+func (h *FeedHistory) archive() bool {
+    return h.Current != ""
+}
+
+// Atom links of the history of a feed document.
+func historyLinks(h FeedHistory) []rsshelper.AtomLink {
+    var links []rsshelper.AtomLink
+    for _, link := range []rsshelper.AtomLink{
+        {Href: h.Current, Rel: "current"},
+        {Href: h.PrevArchive, Rel: "prev-archive"},
+        {Href: h.NextArchive, Rel: "next-archive"},
+    } {
+        if link.Href != "" {
+            links = append(links, link)
+        }
+    }
+    return links
+}
+
+// Archive document of a feed, see GenArchived.
+type FeedArchive struct {
+    // Year of the posts of the archive, or its page number counting from 1
+    // for the oldest posts.
+    Key string
+    // Posts of the archive, newest first.
+    Posts []Post
+    // Generated feed document.
+    Data string
+}
+
+// Sort posts newest first by DatePosted, deleted posts following.
+func sortFeedPosts(posts []Post) {
+    slices.SortStableFunc(posts, func(a, b Post) int {
+        if a.Deleted != b.Deleted {
+            if a.Deleted {
+                return 1
+            }
+            return -1
+        }
+        return b.DatePosted.Compare(a.DatePosted)
+    })
+}
+
+// Split the posts of a feed into closed archives, oldest first. Archives are
+// by the year of the posts, or pages of size posts counting from the oldest
+// post when size is above 0. Only years before the year of the newest post
+// and full pages are closed, so that an archive does not change once written
+// unless one of its posts is edited. Deleted posts are not archived.
+//
+// Pages already written are given by the IDs of their posts, page 1 first.
+// Their posts stay in the same page, including posts deleted since, so that
+// deleting or back-dating a post does not shift every later page. New pages
+// are made of the other posts. Returns the archives and the posts which are
+// not archived, newest first.
+func archivePosts(posts []Post, size int, pages [][]string) ([]FeedArchive, []Post) {
+    var archives []FeedArchive
+    paged := map[string]int{}
+    if size > 0 {
+        for i, page := range pages {
+            archives = append(archives, FeedArchive{Key: strconv.Itoa(i + 1)})
+            for _, id := range page {
+                if _, ok := paged[id]; !ok {
+                    paged[id] = i
+                }
+            }
+        }
+    }
+
+    var live []Post
+    for _, post := range posts {
+        if i, ok := paged[post.ID]; ok {
+            archives[i].Posts = append(archives[i].Posts, post)
+        } else if !post.Deleted {
+            live = append(live, post)
+        }
+    }
+    for i := range archives {
+        sortFeedPosts(archives[i].Posts)
+    }
+    sortFeedPosts(live)
+    if len(live) == 0 {
+        return archives, nil
+    }
+
+    // posts oldest first, consumed by the archives
+    oldest := slices.Clone(live)
+    slices.Reverse(oldest)
+    if size > 0 {
+        for page := len(archives) + 1; len(oldest) >= size; page++ {
+            archived := slices.Clone(oldest[:size])
+            slices.Reverse(archived)
+            archives = append(archives, FeedArchive{Key: strconv.Itoa(page), Posts: archived})
+            oldest = oldest[size:]
+        }
+    } else {
+        currentYear := live[0].DatePosted.Year()
+        for len(oldest) > 0 && oldest[0].DatePosted.Year() < currentYear {
+            year := oldest[0].DatePosted.Year()
+            var archived []Post
+            for len(oldest) > 0 && oldest[0].DatePosted.Year() == year {
+                archived = append([]Post{oldest[0]}, archived...)
+                oldest = oldest[1:]
+            }
+            archives = append(archives, FeedArchive{Key: strconv.Itoa(year), Posts: archived})
+        }
+    }
+    slices.Reverse(oldest)
+    return archives, oldest
+}
+
+// Generator of a feed format from posts.
+func feedGenerator(format string) (func([]Post, *RSSMetadata) (string, error), error) {
+    switch format {
+    case "rss":
+        return genRss, nil
+    case "atom":
+        return genAtom, nil
+    case "json":
+        return genJSONFeed, nil
+    }
+    return nil, fmt.Errorf("unknown feed format '%s'", format)
+}
+
+// Generate an archived feed (RFC 5005) from the microblog document in the
+// format, "rss", "atom" or "json": the current feed of the posts of the
+// window which are not archived and an archive document for each closed
+// archive, see archivePosts. The window is widened so that the current feed
+// has every post which is not archived. Each document links to the current feed at metadata.FeedUrl and
+// to the archives before and after it, published at archiveUrl by key. JSON
+// Feed only links to the previous archive with next_url. The pages already
+// written are given by the IDs or links of their items, page 1 first, to keep
+// their posts in place. Returns the current feed and the archives, oldest
+// first.
+func GenArchived(doc *html.Node, metadata *RSSMetadata, format string, size int, archiveUrl func(key string) string, pages [][]string) (string, []FeedArchive, error) {
+    gen, err := feedGenerator(format)
+    if err != nil {
+        return "", nil, err
+    }
+    posts := parseFeedPosts(doc, metadata)
+
+    // items of written pages by the post they are the permalink of
+    byLink := map[string]string{}
+    for _, post := range posts {
+        byLink[postLink(post, metadata)] = post.ID
+    }
+    var pageIDs [][]string
+    for _, page := range pages {
+        var ids []string
+        for _, item := range page {
+            if id, ok := byLink[item]; ok {
+                ids = append(ids, id)
+            }
+        }
+        pageIDs = append(pageIDs, ids)
+    }
+    archives, open := archivePosts(posts, size, pageIDs)
+
+    for i := range archives {
+        archiveMetadata := *metadata
+        archiveMetadata.FeedUrl = archiveUrl(archives[i].Key)
+        archiveMetadata.History = FeedHistory{Current: metadata.FeedUrl}
+        if i > 0 {
+            archiveMetadata.History.PrevArchive = archiveUrl(archives[i-1].Key)
+        }
+        if i < len(archives) - 1 {
+            archiveMetadata.History.NextArchive = archiveUrl(archives[i+1].Key)
+        }
+        if archives[i].Data, err = gen(archives[i].Posts, &archiveMetadata); err != nil {
+            return "", nil, err
+        }
+    }
+
+    // the current feed has at least every post which is not archived
+    window := metadata.Window
+    if window.Limit > 0 && window.Limit < len(open) {
+        window.Limit = len(open)
+    }
+    if len(open) > 0 {
+        oldestOpen := open[len(open)-1].DatePosted
+        if !window.Since.IsZero() && window.Since.After(oldestOpen) {
+            window.Since = oldestOpen
+        }
+        if !window.Until.IsZero() && window.Until.Before(open[0].DatePosted) {
+            metadata.Warnings.warnf("posts after %s are neither archived nor in the current feed", window.Until.Format("2006-01-02 15:04"))
+        }
+    }
+    currentMetadata := *metadata
+    currentMetadata.History = FeedHistory{}
+    if len(archives) > 0 {
+        currentMetadata.History.PrevArchive = archiveUrl(archives[len(archives)-1].Key)
+    }
+    // archived posts are left out of the current feed, deleted posts which
+    // are not in an archive stay as tombstones
+    archived := map[string]bool{}
+    for _, archive := range archives {
+        for _, post := range archive.Posts {
+            archived[post.ID] = true
+        }
+    }
+    var unarchived []Post
+    for _, post := range posts {
+        if !archived[post.ID] {
+            unarchived = append(unarchived, post)
+        }
+    }
+    current, err := gen(windowPosts(unarchived, window), &currentMetadata)
+    if err != nil {
+        return "", nil, err
+    }
+    return current, archives, nil
+}
@@ -0,0 +1,227 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "encoding/xml"
+    "golang.org/x/net/html"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Testing that archivePosts only archives closed years and full pages,
+// leaving the newest and deleted posts out, and keeps the posts of written
+// pages in place.
+func TestArchivePosts(t *testing.T) {
+    date := func(year int, month time.Month) time.Time {
+        return time.Date(year, month, 1, 12, 0, 0, 0, time.UTC)
+    }
+    posts := []Post{
+        {ID: "e", DatePosted: date(2025, time.March)},
+        {ID: "gone", Deleted: true},
+        {ID: "d", DatePosted: date(2025, time.January)},
+        {ID: "c", DatePosted: date(2024, time.December)},
+        // mis-dated, older than the post below it
+        {ID: "a", DatePosted: date(2023, time.May)},
+        {ID: "b", DatePosted: date(2024, time.June)},
+    }
+
+    archived := func(archives []FeedArchive) string {
+        var s []string
+        for _, a := range archives {
+            s = append(s, a.Key + ":" + postIDs(a.Posts))
+        }
+        return strings.Join(s, " ")
+    }
+
+    cases := []struct {
+        size int
+        pages [][]string
+        archives string
+        open string
+    }{
+        {0, nil, "2023:a 2024:c,b", "e,d"},
+        {2, nil, "1:b,a 2:d,c", "e"},
+        {5, nil, "1:e,d,c,b,a", ""},
+        {6, nil, "", "e,d,c,b,a"},
+        // written pages are ignored by year
+        {0, [][]string{{"b", "a"}}, "2023:a 2024:c,b", "e,d"},
+        // page 1 was written with gone and b, a was back-dated since
+        {2, [][]string{{"b", "gone"}}, "1:b,gone 2:c,a 3:e,d", ""},
+        // an empty written page keeps the numbering
+        {2, [][]string{{"removed"}}, "1: 2:b,a 3:d,c", "e"},
+    }
+    for _, c := range cases {
+        archives, open := archivePosts(posts, c.size, c.pages)
+        if got := archived(archives); got != c.archives {
+            t.Errorf("size %d pages %v expected archives '%s' not '%s'", c.size, c.pages, c.archives, got)
+        }
+        if got := postIDs(open); got != c.open {
+            t.Errorf("size %d pages %v expected open posts '%s' not '%s'", c.size, c.pages, c.open, got)
+        }
+    }
+}
+
+// Testing that GenArchived links the current feed and archives to each other
+// and keeps every post which is not archived in the current feed.
+func TestGenArchived(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(`<html><body><div id="posts">
+        <div class="post" id="new"><div class="date"><time datetime="2025-04-14T12:00:00Z"></time></div><p>new</p></div>
+        <div class="post" id="newer-than-limit"><div class="date"><time datetime="2025-01-14T12:00:00Z"></time></div><p>january</p></div>
+        <div class="post" id="2024"><div class="date"><time datetime="2024-04-14T12:00:00Z"></time></div><p>last year</p></div>
+        <div class="post" id="2022"><div class="date"><time datetime="2022-04-14T12:00:00Z"></time></div><p>long ago</p></div>
+    </div></body></html>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        Author: "yarrie@yarrie.net",
+        Description: "yarrie's microblog",
+        BaseUrl: "http://yarrie.net/microblog",
+        FeedUrl: "http://yarrie.net/microblog/rss.xml",
+        Window: Filter{Limit: 1},
+    }
+    archiveUrl := func(key string) string {
+        return "http://yarrie.net/microblog/rss-" + key + ".xml"
+    }
+    current, archives, err := GenArchived(doc, metadata, "rss", 0, archiveUrl, nil)
+    if err != nil {
+        t.Fatalf("failed to generate archived rss: %s", err)
+    }
+    if len(archives) != 2 || archives[0].Key != "2022" || archives[1].Key != "2024" {
+        t.Fatalf("expected the 2022 and 2024 archives, got %+v", archives)
+    }
+
+    // links by relation and guids of each document
+    decode := func(s string) (*rsshelper.RSS, map[string]string, []string) {
+        var rss rsshelper.RSS
+        if err := xml.Unmarshal([]byte(s), &rss); err != nil {
+            t.Fatalf("failed to decode rss: %s", err)
+        }
+        if problems, err := rsshelper.Validate([]byte(s)); err != nil || rsshelper.HasErrors(problems) {
+            t.Errorf("expected valid rss, got %v (%v)", problems, err)
+        }
+        links := map[string]string{}
+        for _, link := range rss.Channel.AtomLinks {
+            links[link.Rel] = link.Href
+        }
+        var guids []string
+        for _, item := range rss.Channel.Items {
+            guids = append(guids, item.GUID.Value)
+        }
+        return &rss, links, guids
+    }
+
+    rss, links, guids := decode(current)
+    if rss.Channel.Archive != nil {
+        t.Errorf("expected the current feed not to be an archive")
+    }
+    if links["prev-archive"] != archiveUrl("2024") || links["current"] != "" || links["next-archive"] != "" {
+        t.Errorf("unexpected links of the current feed %v", links)
+    }
+    // the limit of 1 is widened to every post of 2025
+    if strings.Join(guids, ",") != "http://yarrie.net/microblog#new,http://yarrie.net/microblog#newer-than-limit" {
+        t.Errorf("unexpected items of the current feed %v", guids)
+    }
+
+    rss, links, guids = decode(archives[0].Data)
+    if rss.Channel.Archive == nil {
+        t.Errorf("expected the 2022 archive to be marked as an archive")
+    }
+    if links["self"] != archiveUrl("2022") || links["current"] != metadata.FeedUrl || links["next-archive"] != archiveUrl("2024") || links["prev-archive"] != "" {
+        t.Errorf("unexpected links of the 2022 archive %v", links)
+    }
+    if strings.Join(guids, ",") != "http://yarrie.net/microblog#2022" {
+        t.Errorf("unexpected items of the 2022 archive %v", guids)
+    }
+
+    _, links, _ = decode(archives[1].Data)
+    if links["prev-archive"] != archiveUrl("2022") || links["next-archive"] != "" {
+        t.Errorf("unexpected links of the 2024 archive %v", links)
+    }
+
+    // archives are stable between generations
+    _, again, err := GenArchived(doc, metadata, "rss", 0, archiveUrl, nil)
+    if err != nil || again[0].Data != archives[0].Data || again[1].Data != archives[1].Data {
+        t.Errorf("expected archives to be generated identically (%v)", err)
+    }
+}
+
+// Testing that GenArchived keeps a deleted post in the written page of its
+// item as a tombstone.
+func TestGenArchivedPages(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(`<html><body><div id="posts">
+        <div class="post" id="c"><div class="date"><time datetime="2025-03-01T12:00:00Z"></time></div><p>c</p></div>
+        <div class="post deleted" id="gone" data-deleted="2025-04-01T12:00:00Z"></div>
+        <div class="post" id="b"><div class="date"><time datetime="2025-02-01T12:00:00Z"></time></div><p>b</p></div>
+    </div></body></html>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        BaseUrl: "http://yarrie.net/microblog",
+        FeedUrl: "http://yarrie.net/microblog/atom.xml",
+    }
+    archiveUrl := func(key string) string {
+        return "http://yarrie.net/microblog/atom-" + key + ".xml"
+    }
+    pages := [][]string{{"http://yarrie.net/microblog#gone", "http://yarrie.net/microblog#b"}}
+    _, archives, err := GenArchived(doc, metadata, "atom", 2, archiveUrl, pages)
+    if err != nil {
+        t.Fatalf("failed to generate archived atom: %s", err)
+    }
+    if len(archives) != 1 || archives[0].Key != "1" {
+        t.Fatalf("expected only the written page, got %+v", archives)
+    }
+    feed, err := rsshelper.DecodeAtom([]byte(archives[0].Data))
+    if err != nil {
+        t.Fatalf("failed to decode archive: %s", err)
+    }
+    if len(feed.Entries) != 1 || feed.Entries[0].ID != "http://yarrie.net/microblog#b" {
+        t.Errorf("expected the page to keep b, got %+v", feed.Entries)
+    }
+    if len(feed.DeletedEntries) != 1 || feed.DeletedEntries[0].Ref != "http://yarrie.net/microblog#gone" {
+        t.Errorf("expected the page to keep the tombstone of gone, got %+v", feed.DeletedEntries)
+    }
+}
+
+// Testing that without a limit the current feed only has the posts which are
+// not archived, as RFC 5005 splits the posts between the current feed and the
+// archives.
+func TestGenArchivedNoLimit(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(`<html><body><div id="posts">
+        <div class="post" id="e"><div class="date"><time datetime="2025-04-05T12:00:00Z"></time></div><p>e</p></div>
+        <div class="post deleted" id="gone" data-deleted="2025-04-04T18:00:00Z"></div>
+        <div class="post" id="d"><div class="date"><time datetime="2025-04-04T12:00:00Z"></time></div><p>d</p></div>
+        <div class="post" id="c"><div class="date"><time datetime="2025-04-03T12:00:00Z"></time></div><p>c</p></div>
+        <div class="post" id="b"><div class="date"><time datetime="2025-04-02T12:00:00Z"></time></div><p>b</p></div>
+        <div class="post" id="a"><div class="date"><time datetime="2025-04-01T12:00:00Z"></time></div><p>a</p></div>
+    </div></body></html>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        BaseUrl: "http://yarrie.net/microblog",
+        FeedUrl: "http://yarrie.net/microblog/rss.xml",
+    }
+    archiveUrl := func(key string) string {
+        return "http://yarrie.net/microblog/rss-" + key + ".xml"
+    }
+    current, archives, err := GenArchived(doc, metadata, "rss", 2, archiveUrl, nil)
+    if err != nil {
+        t.Fatalf("failed to generate archived rss: %s", err)
+    }
+    if len(archives) != 2 || postIDs(archives[0].Posts) != "b,a" || postIDs(archives[1].Posts) != "d,c" {
+        t.Fatalf("expected the pages b,a and d,c, got %+v", archives)
+    }
+    var rss rsshelper.RSS
+    if err := xml.Unmarshal([]byte(current), &rss); err != nil {
+        t.Fatalf("failed to decode rss: %s", err)
+    }
+    if ids := itemIDs(rss.Channel.Items); ids != "e" {
+        t.Errorf("expected the current feed to only have e not '%s'", ids)
+    }
+}
@@ -0,0 +1,257 @@
+package microblog
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Month and weekday names used when formatting display dates.
+type Locale struct {
+    Months [12]string
+    ShortMonths [12]string
+    // Weekdays starting from Sunday, as time.Weekday.
+    Days [7]string
+    ShortDays [7]string
+    // Layout used when the locale is set without a layout.
+    DefaultLayout string
+}
+
+// Lowercase month names of the original display date style.
+var formattedMonths = []string{
+    "jan", "feb", "march", "april", "may", "june", "july", "aug", "sept", "oct", "nov", "dec",
+}
+
+// Supported locales by name.
+var locales = map[string]*Locale{
+    "en": {
+        Months: [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+        ShortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+        Days: [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+        ShortDays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+        DefaultLayout: "%B %-d, %Y",
+    },
+    "fr": {
+        Months: [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+        ShortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+        Days: [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+        ShortDays: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+        DefaultLayout: "%-d %B %Y",
+    },
+    "de": {
+        Months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+        ShortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+        Days: [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+        ShortDays: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+        DefaultLayout: "%-d. %B %Y",
+    },
+    "es": {
+        Months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+        ShortMonths: [12]string{"ene.", "feb.", "mar.", "abr.", "may.", "jun.", "jul.", "ago.", "sept.", "oct.", "nov.", "dic."},
+        Days: [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+        ShortDays: [7]string{"dom.", "lun.", "mar.", "mié.", "jue.", "vie.", "sáb."},
+        DefaultLayout: "%-d de %B de %Y",
+    },
+    "it": {
+        Months: [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+        ShortMonths: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+        Days: [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+        ShortDays: [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+        DefaultLayout: "%-d %B %Y",
+    },
+    "nl": {
+        Months: [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+        ShortMonths: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+        Days: [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+        ShortDays: [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+        DefaultLayout: "%-d %B %Y",
+    },
+    "pt": {
+        Months: [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+        ShortMonths: [12]string{"jan.", "fev.", "mar.", "abr.", "mai.", "jun.", "jul.", "ago.", "set.", "out.", "nov.", "dez."},
+        Days: [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+        ShortDays: [7]string{"dom.", "seg.", "ter.", "qua.", "qui.", "sex.", "sáb."},
+        DefaultLayout: "%-d de %B de %Y",
+    },
+}
+
+// Format of the visible date of posts, and the time zone of the post
+// datetime. The zero value formats dates in the original lowercase style,
+// e.g. "april 10, 2025", in the time zone of the datetime.
+type DateFormat struct {
+    // Go layout (e.g. "2 January 2006") or strftime-style layout when
+    // containing '%' (e.g. "%-d %B %Y"). Month and weekday names use the
+    // locale.
+    Layout string
+    // Locale of month and weekday names, English when nil.
+    Locale *Locale
+    // Time zone dates are converted to, kept as is when nil.
+    Location *time.Location
+}
+
+// Create a date format from config values, each may be empty. The locale is a
+// name such as "en" or "fr", the time zone an IANA name such as
+// "Europe/London". Returns an error for unknown locales or time zones.
+func NewDateFormat(layout string, locale string, timezone string) (*DateFormat, error) {
+    f := &DateFormat{Layout: layout}
+    if locale != "" {
+        l, ok := locales[locale]
+        if !ok {
+            return nil, fmt.Errorf("unknown locale '%s' (expected one of en, fr, de, es, it, nl, pt)", locale)
+        }
+        f.Locale = l
+        if f.Layout == "" {
+            f.Layout = l.DefaultLayout
+        }
+    }
+    if timezone != "" {
+        loc, err := time.LoadLocation(timezone)
+        if err != nil {
+            return nil, fmt.Errorf("unknown timezone '%s': %s", timezone, err)
+        }
+        f.Location = loc
+    }
+    return f, nil
+}
+
+// Convert the time to the format's time zone, if any.
+func (f *DateFormat) In(t time.Time) time.Time {
+    if f != nil && f.Location != nil {
+        return t.In(f.Location)
+    }
+    return t
+}
+
+// Format the visible date of a post. A nil format is the zero value.
+func (f *DateFormat) Format(t time.Time) string {
+    t = f.In(t)
+    if f == nil || f.Layout == "" {
+        return fmt.Sprintf("%s %d, %d", formattedMonths[t.Month()-1], t.Day(), t.Year())
+    }
+    locale := f.Locale
+    if locale == nil {
+        locale = locales["en"]
+    }
+    if strings.Contains(f.Layout, "%") {
+        return strftime(t, f.Layout, locale)
+    }
+    return formatGoLayout(t, f.Layout, locale)
+}
+
+// Go layout name elements, longest first so that "Jan" does not match the
+// start of "January".
+var goNameElements = []string{"January", "Monday", "Jan", "Mon"}
+
+// Format a Go layout with the month and weekday names of the locale. The
+// layout is split around its name elements, the remaining parts are
+// formatted with time.Format.
+func formatGoLayout(t time.Time, layout string, locale *Locale) string {
+    var sb strings.Builder
+    var rest = 0
+    for i := 0; i < len(layout); {
+        var element string
+        for _, e := range goNameElements {
+            if strings.HasPrefix(layout[i:], e) {
+                element = e
+                break
+            }
+        }
+        if element == "" {
+            i++
+            continue
+        }
+        sb.WriteString(t.Format(layout[rest:i]))
+        sb.WriteString(localName(t, element, locale))
+        i += len(element)
+        rest = i
+    }
+    sb.WriteString(t.Format(layout[rest:]))
+    return sb.String()
+}
+
+// Name of the month or weekday of the time for a Go layout name element.
+func localName(t time.Time, element string, locale *Locale) string {
+    switch element {
+    case "January":
+        return locale.Months[t.Month()-1]
+    case "Jan":
+        return locale.ShortMonths[t.Month()-1]
+    case "Monday":
+        return locale.Days[t.Weekday()]
+    default:
+        return locale.ShortDays[t.Weekday()]
+    }
+}
+
+// Format a strftime-style layout. Supports %a %A %b %B %d %e %H %I %m %M %p
+// %S %y %Y %z %Z and %%, a '-' after the '%' removes zero padding of numbers,
+// e.g. %-d. Unknown directives are written as is.
+func strftime(t time.Time, layout string, locale *Locale) string {
+    var sb strings.Builder
+    r := []rune(layout)
+    for i := 0; i < len(r); i++ {
+        if r[i] != '%' || i + 1 >= len(r) {
+            sb.WriteRune(r[i])
+            continue
+        }
+        i++
+        var pad = true
+        if r[i] == '-' && i + 1 < len(r) {
+            pad = false
+            i++
+        }
+        num := func(n int) string {
+            if pad {
+                return fmt.Sprintf("%02d", n)
+            }
+            return fmt.Sprintf("%d", n)
+        }
+        switch r[i] {
+        case 'a':
+            sb.WriteString(locale.ShortDays[t.Weekday()])
+        case 'A':
+            sb.WriteString(locale.Days[t.Weekday()])
+        case 'b':
+            sb.WriteString(locale.ShortMonths[t.Month()-1])
+        case 'B':
+            sb.WriteString(locale.Months[t.Month()-1])
+        case 'd':
+            sb.WriteString(num(t.Day()))
+        case 'e':
+            sb.WriteString(fmt.Sprintf("%2d", t.Day()))
+        case 'H':
+            sb.WriteString(num(t.Hour()))
+        case 'I':
+            hour := t.Hour() % 12
+            if hour == 0 {
+                hour = 12
+            }
+            sb.WriteString(num(hour))
+        case 'm':
+            sb.WriteString(num(int(t.Month())))
+        case 'M':
+            sb.WriteString(num(t.Minute()))
+        case 'p':
+            sb.WriteString(t.Format("PM"))
+        case 'S':
+            sb.WriteString(num(t.Second()))
+        case 'y':
+            sb.WriteString(num(t.Year() % 100))
+        case 'Y':
+            sb.WriteString(fmt.Sprintf("%d", t.Year()))
+        case 'z':
+            sb.WriteString(t.Format("-0700"))
+        case 'Z':
+            sb.WriteString(t.Format("MST"))
+        case '%':
+            sb.WriteRune('%')
+        default:
+            sb.WriteRune('%')
+            if !pad {
+                sb.WriteRune('-')
+            }
+            sb.WriteRune(r[i])
+        }
+    }
+    return sb.String()
+}
@@ -0,0 +1,95 @@
+package microblog
+
+import (
+    "strings"
+    "testing"
+    "time"
+)
+
+// Testing DateFormat.Format with the zero value, Go layouts and strftime-style
+// layouts in different locales.
+func TestDateFormat(t *testing.T) {
+    datetime := time.Date(2025, time.September, 7, 9, 5, 3, 0, time.UTC)
+
+    var zero *DateFormat
+    if s := zero.Format(datetime); s != "sept 7, 2025" {
+        t.Errorf("expected nil format to produce 'sept 7, 2025' not '%s'", s)
+    }
+
+    cases := []struct {
+        layout string
+        locale string
+        expected string
+    }{
+        {"", "", "sept 7, 2025"},
+        {"", "en", "September 7, 2025"},
+        {"", "fr", "7 septembre 2025"},
+        {"Monday 2 January 2006", "de", "Sonntag 7 September 2025"},
+        {"Mon, Jan _2 15:04", "es", "dom., sept.  7 09:05"},
+        {"%A %d/%m/%y %H:%M:%S %%", "it", "domenica 07/09/25 09:05:03 %"},
+        {"%-d %b %Y, %-I%p", "nl", "7 sep 2025, 9AM"},
+    }
+    for _, c := range cases {
+        f, err := NewDateFormat(c.layout, c.locale, "")
+        if err != nil {
+            t.Errorf("failed to create date format '%s' (%s): %s", c.layout, c.locale, err)
+            continue
+        }
+        if s := f.Format(datetime); s != c.expected {
+            t.Errorf("formatting '%s' (%s) expected '%s' not '%s'", c.layout, c.locale, c.expected, s)
+        }
+    }
+
+    // time zone conversion
+    f, err := NewDateFormat("%H:%M", "", "Europe/London")
+    if err != nil {
+        t.Fatalf("failed to create date format with time zone: %s", err)
+    }
+    if s := f.Format(datetime); s != "10:05" {
+        t.Errorf("expected time zone conversion to '10:05' not '%s'", s)
+    }
+
+    if _, err = NewDateFormat("", "xx", ""); err == nil {
+        t.Errorf("expected unknown locale to error")
+    }
+}
+
+// Testing that FormatDates rewrites the visible date of each post from its
+// datetime, leaving deleted posts, undated posts and the rest of the source
+// unchanged.
+func TestFormatDates(t *testing.T) {
+    src := `<div id="posts">
+    <div class="post" id="second">
+        <div class="date"><a href="#second"><time datetime="2025-04-14T23:26:44+01:00"><p>old date</p></time></a></div>
+        <p>second &amp; <time datetime="2020-01-01T00:00:00Z">not the date</time></p>
+    </div>
+    <div class="post deleted" id="gone" data-deleted="2025-04-15T08:00:00Z"></div>
+    <div class="post" id="bare"><div class="date"><time datetime="2025-09-07T09:05:03Z">7/9</time></div></div>
+    <div class="post" id="current"><div class="date"><time datetime="2025-04-10T17:38:10Z"><p>Thursday 10 April 2025</p></time></div></div>
+    <div class="post" id="invalid"><div class="date"><time datetime="yesterday"><p>keep</p></time></div></div>
+    <div class="post" id="undated"><p>no date</p></div>
+</div>`
+    f, err := NewDateFormat("Monday 2 January 2006", "en", "Europe/London")
+    if err != nil {
+        t.Fatalf("failed to create date format: %s", err)
+    }
+    out, changed, err := FormatDates([]byte(src), f)
+    if err != nil {
+        t.Fatalf("failed to format dates: %s", err)
+    }
+    expected := strings.NewReplacer(
+        "<p>old date</p>", "<p>Monday 14 April 2025</p>",
+        `"2025-09-07T09:05:03Z">7/9<`, `"2025-09-07T09:05:03Z">Sunday 7 September 2025<`,
+    ).Replace(src)
+    if string(out) != expected {
+        t.Errorf("unexpected formatted source\nexpected: %s\ngot:      %s", expected, out)
+    }
+    // the current post already has its date
+    if changed != 2 {
+        t.Errorf("expected 2 posts changed not %d", changed)
+    }
+
+    if again, changed, err := FormatDates(out, f); err != nil || changed != 0 || string(again) != string(out) {
+        t.Errorf("expected formatting twice to change nothing (%d changed, %v)", changed, err)
+    }
+}
@@ -0,0 +1,47 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "fmt"
+    "html"
+    "time"
+)
+
+// Remove the post with the given ID from the HTML source. When tombstone is
+// true the post is replaced with an empty marker which keeps its ID, e.g.
+// <div class="post deleted" id="..." data-deleted="...">, so that outputs
+// which support deletion can tell feed consumers the post is gone. Deleting
+// a tombstone without a new tombstone removes the marker entirely. The rest
+// of the source is left byte for byte unchanged. Returns the new source.
+func DeletePost(src []byte, id string, tombstone bool, datetime time.Time) ([]byte, error) {
+    elements, err := htmlhelper.IndexSource(src)
+    if err != nil {
+        return nil, err
+    }
+    post := findPost(elements, id)
+    if post == nil {
+        return nil, fmt.Errorf("post '%s' not found", id)
+    }
+
+    if tombstone {
+        if post.HasClass("deleted") {
+            return nil, fmt.Errorf("post '%s' is already deleted", id)
+        }
+        marker := fmt.Sprintf(`<div class="post deleted" id="%s" data-deleted="%s"></div>`, html.EscapeString(id), datetime.Format(time.RFC3339))
+        return htmlhelper.Splice(src, post.Start, post.End, marker), nil
+    }
+
+    // remove the indentation and new line preceding the post so no blank
+    // line is left behind
+    start := post.Start
+    for start > 0 && (src[start-1] == ' ' || src[start-1] == '\t') {
+        start--
+    }
+    if start > 0 && src[start-1] == '\n' {
+        start--
+        if start > 0 && src[start-1] == '\r' {
+            start--
+        }
+    }
+    return htmlhelper.Splice(src, start, post.End, ""), nil
+}
@@ -0,0 +1,78 @@
+package microblog
+
+import (
+    "strings"
+    "testing"
+    "time"
+)
+
+// Hand formatted microblog with irregular indentation, attribute quoting and
+// comments between the posts.
+var handFormattedMicroblog = `<div id="posts">
+  <!-- newest first -->
+  <div class=post id='second'>
+	<div class="date"><time datetime="2025-04-14T12:26:44+01:00">april 14</time></div>
+	<p>second</p>
+  </div>
+
+      <div id="first"   class="post">
+          <div class="date"><time datetime="2025-04-10T17:38:10+01:00">april 10</time></div>
+          <p>first<br>
+          post</p></div>
+  <p>footer</p>
+</div>`
+
+// Testing that DeletePost removes a post with its indentation and line and
+// leaves the rest of the source unchanged.
+func TestDeletePost(t *testing.T) {
+    src, err := DeletePost([]byte(handFormattedMicroblog), "first", false, time.Time{})
+    if err != nil {
+        t.Fatalf("failed to delete post: %s", err)
+    }
+    expected := strings.Replace(handFormattedMicroblog, `
+      <div id="first"   class="post">
+          <div class="date"><time datetime="2025-04-10T17:38:10+01:00">april 10</time></div>
+          <p>first<br>
+          post</p></div>`, "", 1)
+    if string(src) != expected {
+        t.Errorf("unexpected source after deleting\nexpected: %s\ngot:      %s", expected, src)
+    }
+
+    if _, err := DeletePost([]byte(handFormattedMicroblog), "missing", false, time.Time{}); err == nil {
+        t.Errorf("expected deleting an unknown post to fail")
+    }
+}
+
+// Testing that DeletePost with a tombstone replaces the post by a marker in
+// place, refuses to tombstone it again and removes the marker on a plain
+// delete.
+func TestDeletePostTombstone(t *testing.T) {
+    deleted := aprilUTC(15, 8)
+    src, err := DeletePost([]byte(handFormattedMicroblog), "second", true, deleted)
+    if err != nil {
+        t.Fatalf("failed to tombstone post: %s", err)
+    }
+    expected := strings.Replace(handFormattedMicroblog, `<div class=post id='second'>
+	<div class="date"><time datetime="2025-04-14T12:26:44+01:00">april 14</time></div>
+	<p>second</p>
+  </div>`, `<div class="post deleted" id="second" data-deleted="2025-04-15T08:00:00Z"></div>`, 1)
+    if string(src) != expected {
+        t.Errorf("unexpected source after tombstoning\nexpected: %s\ngot:      %s", expected, src)
+    }
+
+    if _, err := DeletePost(src, "second", true, deleted); err == nil {
+        t.Errorf("expected tombstoning a tombstone to fail")
+    }
+    src, err = DeletePost(src, "second", false, time.Time{})
+    if err != nil {
+        t.Fatalf("failed to delete tombstone: %s", err)
+    }
+    expected = strings.Replace(handFormattedMicroblog, `
+  <div class=post id='second'>
+	<div class="date"><time datetime="2025-04-14T12:26:44+01:00">april 14</time></div>
+	<p>second</p>
+  </div>`, "", 1)
+    if string(src) != expected {
+        t.Errorf("unexpected source after deleting the tombstone\nexpected: %s\ngot:      %s", expected, src)
+    }
+}
@@ -0,0 +1,109 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "fmt"
+    "strings"
+)
+
+// Find the .post element with the given ID within the source elements.
+// Returns nil when no post exists.
+func findPost(elements []*htmlhelper.SourceElement, id string) *htmlhelper.SourceElement {
+    return htmlhelper.FindSourceElement(elements, func(e *htmlhelper.SourceElement) bool {
+        return e.ID == id && e.HasClass("post")
+    })
+}
+
+// Find the div.date child of a post. Returns nil when the post is missing its
+// date.
+func findPostDate(post *htmlhelper.SourceElement) *htmlhelper.SourceElement {
+    for _, child := range post.Children {
+        if child.Name == "div" && child.HasClass("date") {
+            return child
+        }
+    }
+    return nil
+}
+
+// Check that an edited post body is suitable to be spliced into a post. The
+// body must have balanced tags and contain at least one element or
+// non-whitespace text.
+func checkPostBody(s string) error {
+    if strings.TrimSpace(s) == "" {
+        return fmt.Errorf("edited post is empty")
+    }
+    if err := htmlhelper.CheckBalanced(s); err != nil {
+        return fmt.Errorf("edited post is invalid: %s", err)
+    }
+    return nil
+}
+
+// Split the raw source of a post body into its surrounding whitespace and
+// content, with the indentation of the content removed. Returns the leading
+// whitespace, content, trailing whitespace and the indentation.
+func splitPostBody(raw string) (string, string, string, string) {
+    content := strings.TrimSpace(raw)
+    if content == "" {
+        return raw, "", "", ""
+    }
+    start := strings.Index(raw, content)
+    leading, trailing := raw[:start], raw[start+len(content):]
+    // indentation is the whitespace following the last new line
+    indent := leading[strings.LastIndex(leading, "\n")+1:]
+
+    lines := strings.Split(content, "\n")
+    for i := range lines {
+        lines[i] = strings.TrimPrefix(lines[i], indent)
+    }
+    return leading, strings.Join(lines, "\n"), trailing, indent
+}
+
+// Indent each line of the content after the first, the first line follows
+// the leading whitespace. Empty lines are not indented.
+func indentPostBody(content string, indent string) string {
+    lines := strings.Split(content, "\n")
+    for i := 1; i < len(lines); i++ {
+        if lines[i] != "" {
+            lines[i] = indent + lines[i]
+        }
+    }
+    return strings.Join(lines, "\n")
+}
+
+// Replace the body of the post with the given ID using the edit function. The
+// edit function receives the HTML source of the body (everything after
+// div.date) with its indentation removed and returns the edited source. The
+// edited source is checked and re-indented before being spliced into the
+// HTML source, the rest of the source is left byte for byte unchanged. On
+// failure the source is left untouched. Returns the new source and whether
+// the body changed.
+func EditPost(src []byte, id string, edit func(body string) (string, error)) ([]byte, bool, error) {
+    elements, err := htmlhelper.IndexSource(src)
+    if err != nil {
+        return nil, false, err
+    }
+    post := findPost(elements, id)
+    if post == nil {
+        return nil, false, fmt.Errorf("post '%s' not found", id)
+    }
+    date := findPostDate(post)
+    if date == nil {
+        return nil, false, fmt.Errorf("post '%s' is missing its date", id)
+    }
+
+    leading, original, trailing, indent := splitPostBody(string(src[date.End:post.ContentEnd]))
+    edited, err := edit(original + "\n")
+    if err != nil {
+        return nil, false, err
+    }
+    edited = strings.TrimSpace(edited)
+    if edited == original {
+        return src, false, nil
+    }
+    if err := checkPostBody(edited); err != nil {
+        return nil, false, err
+    }
+
+    body := leading + indentPostBody(edited, indent) + trailing
+    return htmlhelper.Splice(src, date.End, post.ContentEnd, body), true, nil
+}
@@ -0,0 +1,79 @@
+package microblog
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+)
+
+var editMicroblog = `<div id="posts">
+    <div class="post" id="second">
+        <div class="date"><a href="#second"><time datetime="2025-04-14T12:26:44+01:00"><p>april 14, 2025</p></time></a></div>
+        <p>second</p>
+        <ul>
+            <li>item</li>
+        </ul>
+    </div>
+    <div class="post" id="first">
+        <div class="date"><a href="#first"><time datetime="2025-04-10T17:38:10+01:00"><p>april 10, 2025</p></time></a></div>
+        <p>first</p>
+    </div>
+</div>`
+
+// Testing that EditPost gives the edit function the unindented body of the
+// post and splices the re-indented result in its place.
+func TestEditPost(t *testing.T) {
+    var received string
+    src, changed, err := EditPost([]byte(editMicroblog), "second", func(body string) (string, error) {
+        received = body
+        return "<p>edited</p>\n<p>\n    nested\n</p>\n\n", nil
+    })
+    if err != nil {
+        t.Fatalf("failed to edit post: %s", err)
+    }
+    if received != "<p>second</p>\n<ul>\n    <li>item</li>\n</ul>\n" {
+        t.Errorf("unexpected body given to the edit function '%s'", received)
+    }
+    expected := strings.Replace(editMicroblog, `<p>second</p>
+        <ul>
+            <li>item</li>
+        </ul>`, `<p>edited</p>
+        <p>
+            nested
+        </p>`, 1)
+    if !changed || string(src) != expected {
+        t.Errorf("unexpected edited source (changed %t)\nexpected: %s\ngot:      %s", changed, expected, src)
+    }
+}
+
+// Testing that EditPost reports an unchanged body and rejects empty, invalid
+// and failed edits and unknown posts.
+func TestEditPostRejected(t *testing.T) {
+    src, changed, err := EditPost([]byte(editMicroblog), "first", func(body string) (string, error) {
+        return body, nil
+    })
+    if err != nil || changed || string(src) != editMicroblog {
+        t.Errorf("expected an unchanged edit to leave the source as is (changed %t, %v)", changed, err)
+    }
+
+    edits := map[string]func(string) (string, error){
+        "empty": func(string) (string, error) {
+            return " \n", nil
+        },
+        "unbalanced": func(string) (string, error) {
+            return "<p>unclosed <a href=\"x\">link</p>", nil
+        },
+        "failed": func(string) (string, error) {
+            return "", fmt.Errorf("editor failed")
+        },
+    }
+    for name, edit := range edits {
+        if _, _, err := EditPost([]byte(editMicroblog), "first", edit); err == nil {
+            t.Errorf("expected the %s edit to be rejected", name)
+        }
+    }
+
+    if _, _, err := EditPost([]byte(editMicroblog), "missing", edits["empty"]); err == nil || !strings.Contains(err.Error(), "not found") {
+        t.Errorf("expected an unknown post to be not found, got %v", err)
+    }
+}
@@ -0,0 +1,194 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "golang.org/x/net/html"
+    "fmt"
+    "mime"
+    "net/url"
+    "path"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Post rendered for a feed, shared by every output format so that each format
+// only maps the fields to its own model.
+type feedPost struct {
+    Post Post
+    // Permalink of the post, the base url with the post ID as fragment.
+    Link string
+    // Unique ID of the item of the post, the permalink unless merged with
+    // an item of a previous feed.
+    ID string
+    // Publication date of the item, the date of the post unless merged.
+    Published time.Time
+    // Last time the item changed, zero when never changed.
+    Updated time.Time
+    // Copy of the nodes of the post with URLs resolved against the permalink,
+    // see htmlhelper.AbsoluteURLs.
+    Nodes []*html.Node
+    // Element nodes of the post rendered as HTML.
+    HTML string
+    // Plain text rendering of the post.
+    Text string
+    // Media referenced by the post.
+    Media []postMedia
+}
+
+// Media element of a post, e.g. an image or audio file.
+type postMedia struct {
+    // Name of the element referencing the media, img, audio or video.
+    Element string
+    // URL of the media resolved against the permalink of the post.
+    URL string
+    // MIME type sniffed from the local file, otherwise guessed from the
+    // file extension, may be empty.
+    Type string
+    // Alternative text of images.
+    Alt string
+    // Dimensions of images in pixels from the element attributes or the
+    // local file, 0 when unknown.
+    Width int
+    Height int
+    // Size in bytes of the local file, 0 when unknown.
+    Size int64
+}
+
+// Permalink of a post, the base url with the post ID as fragment.
+func postLink(post Post, metadata *RSSMetadata) string {
+    return fmt.Sprintf("%s#%s", metadata.BaseUrl, post.ID)
+}
+
+// Render the element nodes of a post as HTML.
+func renderPostContent(nodes []*html.Node) (string, error) {
+    var b strings.Builder
+    for _, node := range nodes {
+        if node.Type != html.ElementNode {
+            continue
+        }
+        err := html.Render(&b, node)
+        // render nodes
+        if err != nil {
+            return "", err
+        }
+    }
+    return b.String(), nil
+}
+
+// Render a post for a feed. Deleted posts are only given their link.
+func newFeedPost(post Post, metadata *RSSMetadata) (*feedPost, error) {
+    fp := &feedPost{
+        Post: post,
+        Link: postLink(post, metadata),
+        Published: post.DatePosted,
+        Updated: post.DateModified,
+    }
+    fp.ID = fp.Link
+    if post.Deleted {
+        return fp, nil
+    }
+    // relative URLs break in feed readers, resolve them in a copy so that the
+    // document is left unchanged
+    base, err := url.Parse(fp.Link)
+    if err != nil {
+        return nil, fmt.Errorf("invalid permalink '%s' of post %s: %w", fp.Link, post.ID, err)
+    }
+    fp.Nodes = htmlhelper.AbsoluteURLs(post.Nodes, base)
+    if metadata.Sanitize != nil {
+        var report *htmlhelper.SanitizeReport
+        fp.Nodes, report = htmlhelper.Sanitize(fp.Nodes, metadata.Sanitize)
+        if !report.Empty() {
+            metadata.Warnings.warnf("stripped from post %s: %s", post.ID, report)
+        }
+    }
+    rendered, err := renderPostContent(fp.Nodes)
+    if err != nil {
+        return nil, err
+    }
+    fp.HTML = rendered
+    fp.Text = post.Text(false).String()
+    if metadata.Merge != nil {
+        metadata.Merge.apply(fp)
+    }
+    fp.Media = findPostMedia(fp.Nodes, fp.Link)
+    if metadata.SiteRoot != "" {
+        for i := range fp.Media {
+            statMedia(post, &fp.Media[i], metadata)
+        }
+    }
+    return fp, nil
+}
+
+// MIME types of common audio and video extensions, which are missing from
+// the built-in table of the mime package on systems without mime.types.
+var mediaTypes = map[string]string{
+    ".mp3": "audio/mpeg",
+    ".m4a": "audio/mp4",
+    ".ogg": "audio/ogg",
+    ".opus": "audio/ogg",
+    ".wav": "audio/wav",
+    ".flac": "audio/flac",
+    ".mp4": "video/mp4",
+    ".m4v": "video/mp4",
+    ".webm": "video/webm",
+    ".mov": "video/quicktime",
+}
+
+// Guess the MIME type of a file extension, empty when unknown.
+func mediaType(ext string) string {
+    if t, ok := mediaTypes[strings.ToLower(ext)]; ok {
+        return t
+    }
+    return mime.TypeByExtension(ext)
+}
+
+// Find the images, audio and video referenced by the nodes of a post,
+// including <source> children of audio and video. URLs are resolved against
+// the base. Media without a src are skipped.
+func findPostMedia(nodes []*html.Node, base string) []postMedia {
+    baseUrl, err := url.Parse(base)
+    if err != nil {
+        return nil
+    }
+    var media []postMedia
+    for _, node := range nodes {
+        htmlhelper.WalkHtmlDoc(node, func(wn *htmlhelper.NodeWrapper, e htmlhelper.WalkEvent) bool {
+            if e != htmlhelper.WalkEnter {
+                return true
+            }
+            element := wn.ElementType
+            if element == "source" && wn.Node.Parent != nil {
+                element = wn.Node.Parent.Data
+            }
+            if element != "img" && element != "audio" && element != "video" {
+                return true
+            }
+            src := htmlhelper.GetNodeAttr(wn.Node, "src")
+            if src == "" {
+                return true
+            }
+            ref, err := url.Parse(src)
+            if err != nil {
+                return true
+            }
+            resolved := baseUrl.ResolveReference(ref)
+            mimeType := htmlhelper.GetNodeAttr(wn.Node, "type")
+            if mimeType == "" {
+                mimeType = mediaType(path.Ext(resolved.Path))
+            }
+            width, _ := strconv.Atoi(htmlhelper.GetNodeAttr(wn.Node, "width"))
+            height, _ := strconv.Atoi(htmlhelper.GetNodeAttr(wn.Node, "height"))
+            media = append(media, postMedia{
+                Element: element,
+                URL: resolved.String(),
+                Type: mimeType,
+                Alt: htmlhelper.GetNodeAttr(wn.Node, "alt"),
+                Width: width,
+                Height: height,
+            })
+            return true
+        })
+    }
+    return media
+}
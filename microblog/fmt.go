@@ -0,0 +1,63 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "html"
+    "slices"
+    "time"
+)
+
+// Rewrite the visible date of every post from its <time datetime> using the
+// date format. The visible date is the content of the <p> within the <time>,
+// or the <time> itself when it has no <p>. Posts with a missing or invalid
+// datetime are skipped. The rest of the source is left byte for byte
+// unchanged. Returns the new source and the number of posts changed.
+func FormatDates(src []byte, f *DateFormat) ([]byte, int, error) {
+    elements, err := htmlhelper.IndexSource(src)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    // replacement of the content of an element
+    type replacement struct {
+        element *htmlhelper.SourceElement
+        text string
+    }
+    var replacements []replacement
+    for _, post := range elements {
+        if !post.HasClass("post") || post.HasClass("deleted") {
+            continue
+        }
+        date := findPostDate(post)
+        if date == nil {
+            continue
+        }
+        timeElement := date.Find(func(e *htmlhelper.SourceElement) bool {
+            return e.Name == "time"
+        })
+        if timeElement == nil {
+            continue
+        }
+        datetime, err := time.Parse(time.RFC3339, timeElement.GetAttr("datetime"))
+        if err != nil {
+            continue
+        }
+
+        visible := timeElement.Find(func(e *htmlhelper.SourceElement) bool {
+            return e.Name == "p"
+        })
+        if visible == nil {
+            visible = timeElement
+        }
+        text := html.EscapeString(f.Format(datetime))
+        if string(src[visible.ContentStart:visible.ContentEnd]) != text {
+            replacements = append(replacements, replacement{visible, text})
+        }
+    }
+
+    // splice from the end so earlier offsets stay valid
+    for _, r := range slices.Backward(replacements) {
+        src = htmlhelper.Splice(src, r.element.ContentStart, r.element.ContentEnd, r.text)
+    }
+    return src, len(replacements), nil
+}
@@ -0,0 +1,138 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "golang.org/x/net/html"
+    "os"
+    "time"
+)
+
+// Updated time of a feed without any dated entries, a fixed date so that the
+// output only changes with the document.
+var emptyFeedUpdated = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// When a deleted post was deleted, otherwise when it was last modified or
+// posted, zero if none is known.
+func deletedWhen(post Post) time.Time {
+    for _, date := range []time.Time{post.DateDeleted, post.DateModified, post.DatePosted} {
+        if !date.IsZero() {
+            return date
+        }
+    }
+    return time.Time{}
+}
+
+func postToAtomEntry(post Post, metadata *RSSMetadata) (*rsshelper.AtomEntry, error) {
+    fp, err := newFeedPost(post, metadata)
+    if err != nil {
+        return nil, err
+    }
+    published := fp.Published
+    updated := fp.Published
+    if fp.Updated.After(updated) {
+        updated = fp.Updated
+    }
+    var categories []rsshelper.AtomCategory
+    for _, tag := range post.Tags {
+        categories = append(categories, rsshelper.AtomCategory{Term: tag})
+    }
+    return &rsshelper.AtomEntry{
+        ID: fp.ID,
+        // atom requires a title, untitled posts use their first sentence
+        Title: post.DerivedTitle(metadata.titleLength()),
+        Updated: updated,
+        Published: &published,
+        Links: []rsshelper.AtomLink{
+            {Href: fp.Link, Rel: "alternate", Type: "text/html"},
+        },
+        Categories: categories,
+        Summary: &rsshelper.AtomContent{
+            Type: "text",
+            Body: post.Summary(metadata.summaryLength()),
+        },
+        Content: &rsshelper.AtomContent{
+            Type: "html",
+            Body: fp.HTML,
+        },
+    }, nil
+}
+
+// Generate an Atom 1.0 feed from the microblog document, with the posts of the
+// window newest first. The feed ID is the
+// base url and each entry ID the permalink of its post. Deleted posts are
+// included as tombstones (RFC 6721), which require a date, tombstones without
+// any are left out. The feed is updated at the newest post or deletion, so
+// that the output only changes with the document.
+func GenAtom(doc *html.Node, metadata *RSSMetadata) (string, error) {
+    return genAtom(feedPosts(doc, metadata), metadata)
+}
+
+// Generate an Atom 1.0 feed of the posts, see GenAtom.
+func genAtom(posts []Post, metadata *RSSMetadata) (string, error) {
+    feed := rsshelper.AtomFeed{
+        ID: metadata.BaseUrl,
+        Title: metadata.Title,
+        Subtitle: metadata.Description,
+        Author: &rsshelper.AtomPerson{Name: metadata.Author},
+        Links: []rsshelper.AtomLink{
+            {Href: metadata.BaseUrl, Rel: "alternate", Type: "text/html"},
+        },
+    }
+    if metadata.FeedUrl != "" {
+        feed.Links = append(feed.Links, rsshelper.AtomLink{
+            Href: metadata.FeedUrl,
+            Rel: "self",
+            Type: "application/atom+xml",
+        })
+    }
+    for _, link := range historyLinks(metadata.History) {
+        link.Type = "application/atom+xml"
+        feed.Links = append(feed.Links, link)
+    }
+    if metadata.History.archive() {
+        feed.Archive = &rsshelper.HistoryArchive{}
+    }
+
+    for _, post := range posts {
+        if post.Deleted {
+            when := deletedWhen(post)
+            if when.IsZero() {
+                metadata.Warnings.warnf("deleted post %s has no data-deleted date, leaving out its tombstone", post.ID)
+                continue
+            }
+            feed.DeletedEntries = append(feed.DeletedEntries, rsshelper.AtomDeletedEntry{
+                Ref: postLink(post, metadata),
+                When: when,
+            })
+            if when.After(feed.Updated) {
+                feed.Updated = when
+            }
+            continue
+        }
+        entry, err := postToAtomEntry(post, metadata)
+        if err != nil {
+            return "", err
+        }
+        feed.Entries = append(feed.Entries, *entry)
+        if entry.Updated.After(feed.Updated) {
+            feed.Updated = entry.Updated
+        }
+    }
+    if feed.Updated.IsZero() {
+        feed.Updated = emptyFeedUpdated
+    }
+
+    data, err := rsshelper.EncodeAtom(&feed)
+    if err != nil {
+        return "", err
+    }
+    return string(data), nil
+}
+
+func GenAtomFromFile(f *os.File, metadata *RSSMetadata) (string, error) {
+    doc, err := html.Parse(f)
+    if err != nil {
+        return "", err
+    }
+    return GenAtom(doc, metadata)
+}
@@ -0,0 +1,122 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "golang.org/x/net/html"
+    "strings"
+    "testing"
+)
+
+var exampleMicroblog = `<html><body><div id="posts">
+    <div class="post" id="second" data-tags="music, news" data-modified="2025-04-14T13:00:00+01:00">
+        <div class="date"><a href="#second"><time datetime="2025-04-14T12:26:44+01:00"><p>april 14, 2025</p></time></a></div>
+        <p>second &amp; <a href="https://example.com">newest</a></p>
+        <audio controls><source src="../audio/song.mp3"></audio>
+    </div>
+    <div class="post deleted" id="gone" data-deleted="2025-04-15T08:00:00Z"></div>
+    <div class="post" id="first">
+        <div class="date"><a href="#first"><time datetime="2025-04-10T17:38:10+01:00"><p>april 10, 2025</p></time></a></div>
+        <p>first</p>
+    </div>
+</div></body></html>`
+
+// Testing that GenAtom produces entries and tombstones which decode with the
+// expected IDs, links and content.
+func TestGenAtom(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(exampleMicroblog))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        Author: "yarrie",
+        BaseUrl: "http://yarrie.net/microblog",
+        FeedUrl: "http://yarrie.net/microblog/atom.xml",
+    }
+    s, err := GenAtom(doc, metadata)
+    if err != nil {
+        t.Fatalf("failed to generate atom: %s", err)
+    }
+    feed, err := rsshelper.DecodeAtom([]byte(s))
+    if err != nil {
+        t.Fatalf("failed to decode generated atom: %s", err)
+    }
+
+    if feed.ID != metadata.BaseUrl {
+        t.Errorf("expected feed id '%s' not '%s'", metadata.BaseUrl, feed.ID)
+    }
+    var self string
+    for _, link := range feed.Links {
+        if link.Rel == "self" {
+            self = link.Href
+        }
+    }
+    if self != metadata.FeedUrl {
+        t.Errorf("expected self link '%s' not '%s'", metadata.FeedUrl, self)
+    }
+    // deletion is the newest change
+    if feed.Updated.Format("2006-01-02T15:04:05Z07:00") != "2025-04-15T08:00:00Z" {
+        t.Errorf("expected feed updated at the deletion not %s", feed.Updated)
+    }
+
+    if len(feed.Entries) != 2 {
+        t.Fatalf("expected 2 entries, got %d", len(feed.Entries))
+    }
+    entry := feed.Entries[0]
+    if entry.ID != "http://yarrie.net/microblog#second" {
+        t.Errorf("expected entry id to be the permalink not '%s'", entry.ID)
+    }
+    if entry.Content == nil || entry.Content.Type != "html" || entry.Content.Body != `<p>second &amp; <a href="https://example.com">newest</a></p><audio controls=""><source src="http://yarrie.net/audio/song.mp3"/></audio>` {
+        t.Errorf("unexpected entry content %v", entry.Content)
+    }
+    if len(entry.Categories) != 2 || entry.Categories[0].Term != "music" || entry.Categories[1].Term != "news" {
+        t.Errorf("expected the tags of the post as categories, got %v", entry.Categories)
+    }
+    if len(feed.DeletedEntries) != 1 || feed.DeletedEntries[0].Ref != "http://yarrie.net/microblog#gone" {
+        t.Errorf("expected tombstone of 'gone', got %v", feed.DeletedEntries)
+    }
+}
+
+// Testing that a tombstone without data-deleted falls back to the modified
+// date or is left out, and that an empty feed has a fixed updated date.
+func TestGenAtomUndated(t *testing.T) {
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        BaseUrl: "http://yarrie.net/microblog",
+    }
+    doc, err := html.Parse(strings.NewReader(`<html><body><div id="posts">
+        <div class="post deleted" id="modified" data-modified="2025-04-12T08:00:00Z"></div>
+        <div class="post deleted" id="undated"></div>
+    </div></body></html>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    s, err := GenAtom(doc, metadata)
+    if err != nil {
+        t.Fatalf("failed to generate atom: %s", err)
+    }
+    feed, err := rsshelper.DecodeAtom([]byte(s))
+    if err != nil {
+        t.Fatalf("failed to decode generated atom: %s", err)
+    }
+    if len(feed.DeletedEntries) != 1 || feed.DeletedEntries[0].Ref != "http://yarrie.net/microblog#modified" {
+        t.Fatalf("expected only the tombstone of 'modified', got %v", feed.DeletedEntries)
+    }
+    if when := feed.DeletedEntries[0].When.Format("2006-01-02T15:04:05Z07:00"); when != "2025-04-12T08:00:00Z" {
+        t.Errorf("expected the tombstone at the modified date not %s", when)
+    }
+
+    doc, err = html.Parse(strings.NewReader(`<html><body><div id="posts"></div></body></html>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    if s, err = GenAtom(doc, metadata); err != nil {
+        t.Fatalf("failed to generate atom: %s", err)
+    }
+    if feed, err = rsshelper.DecodeAtom([]byte(s)); err != nil {
+        t.Fatalf("failed to decode generated atom: %s", err)
+    }
+    if !feed.Updated.Equal(emptyFeedUpdated) {
+        t.Errorf("expected the empty feed updated at %s not %s", emptyFeedUpdated, feed.Updated)
+    }
+}
@@ -0,0 +1,106 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "golang.org/x/net/html"
+    "os"
+)
+
+func postToJSONItem(post Post, metadata *RSSMetadata) (*rsshelper.JSONItem, error) {
+    fp, err := newFeedPost(post, metadata)
+    if err != nil {
+        return nil, err
+    }
+
+    published := fp.Published
+    item := &rsshelper.JSONItem{
+        ID: fp.ID,
+        URL: fp.Link,
+        // microblog posts are untitled unless explicitly given one
+        Title: post.ExplicitTitle(),
+        ContentHTML: fp.HTML,
+        ContentText: fp.Text,
+        Summary: post.Summary(metadata.summaryLength()),
+        DatePublished: &published,
+        Tags: post.Tags,
+    }
+    if !fp.Updated.IsZero() {
+        modified := fp.Updated
+        item.DateModified = &modified
+    }
+    if metadata.Author != "" {
+        item.Authors = []rsshelper.JSONAuthor{{Name: metadata.Author}}
+    }
+    for _, media := range fp.Media {
+        // attachments require a mime type
+        if media.Type == "" {
+            continue
+        }
+        item.Attachments = append(item.Attachments, rsshelper.JSONAttachment{
+            URL: media.URL,
+            MimeType: media.Type,
+            SizeInBytes: media.Size,
+        })
+    }
+    return item, nil
+}
+
+// Generate a JSON Feed 1.1 from the microblog document, with the posts of the
+// window newest first. Each item ID is the
+// permalink of its post. Deleted posts are not items, which readers would
+// show as blank posts, but are listed by the top level _yarrienet extension.
+func GenJSONFeed(doc *html.Node, metadata *RSSMetadata) (string, error) {
+    return genJSONFeed(feedPosts(doc, metadata), metadata)
+}
+
+// Generate a JSON Feed 1.1 of the posts, see GenJSONFeed.
+func genJSONFeed(posts []Post, metadata *RSSMetadata) (string, error) {
+    feed := rsshelper.JSONFeed{
+        Version: rsshelper.JSONFeedVersion,
+        Title: metadata.Title,
+        HomePageURL: metadata.BaseUrl,
+        FeedURL: metadata.FeedUrl,
+        Description: metadata.Description,
+        Language: metadata.Language,
+        // json feed only paginates to older items
+        NextURL: metadata.History.PrevArchive,
+        Items: []rsshelper.JSONItem{},
+    }
+    if metadata.Author != "" {
+        feed.Authors = []rsshelper.JSONAuthor{{Name: metadata.Author}}
+    }
+    for _, post := range posts {
+        if post.Deleted {
+            // json feed cannot represent deletion
+            if feed.Yarrienet == nil {
+                feed.Yarrienet = &rsshelper.JSONFeedExtension{}
+            }
+            deleted := rsshelper.JSONDeletedItem{ID: postLink(post, metadata)}
+            if !post.DateDeleted.IsZero() {
+                date := post.DateDeleted
+                deleted.DateDeleted = &date
+            }
+            feed.Yarrienet.Deleted = append(feed.Yarrienet.Deleted, deleted)
+            continue
+        }
+        item, err := postToJSONItem(post, metadata)
+        if err != nil {
+            return "", err
+        }
+        feed.Items = append(feed.Items, *item)
+    }
+
+    data, err := rsshelper.EncodeJSONFeed(&feed)
+    if err != nil {
+        return "", err
+    }
+    return string(data), nil
+}
+
+func GenJSONFeedFromFile(f *os.File, metadata *RSSMetadata) (string, error) {
+    doc, err := html.Parse(f)
+    if err != nil {
+        return "", err
+    }
+    return GenJSONFeed(doc, metadata)
+}
@@ -0,0 +1,72 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "golang.org/x/net/html"
+    "strings"
+    "testing"
+)
+
+// Testing that GenJSONFeed produces items with content, tags and attachments
+// which decode as expected, listing deleted posts outside of the items.
+func TestGenJSONFeed(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(exampleMicroblog))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        Author: "yarrie",
+        BaseUrl: "http://yarrie.net/microblog/",
+        FeedUrl: "http://yarrie.net/microblog/feed.json",
+        Language: "en-GB",
+    }
+    s, err := GenJSONFeed(doc, metadata)
+    if err != nil {
+        t.Fatalf("failed to generate json feed: %s", err)
+    }
+    feed, err := rsshelper.DecodeJSONFeed([]byte(s))
+    if err != nil {
+        t.Fatalf("failed to decode generated json feed: %s", err)
+    }
+
+    if feed.Version != rsshelper.JSONFeedVersion || feed.FeedURL != metadata.FeedUrl || feed.Language != "en-GB" {
+        t.Errorf("unexpected feed version '%s', feed url '%s' or language '%s'", feed.Version, feed.FeedURL, feed.Language)
+    }
+    if len(feed.Items) != 2 {
+        t.Fatalf("expected 2 items, got %d", len(feed.Items))
+    }
+
+    item := feed.Items[0]
+    if item.ID != "http://yarrie.net/microblog/#second" || item.URL != item.ID {
+        t.Errorf("expected item id and url to be the permalink not '%s' and '%s'", item.ID, item.URL)
+    }
+    if item.ContentText != "second & newest" {
+        t.Errorf("expected content text 'second & newest' not '%s'", item.ContentText)
+    }
+    if strings.Join(item.Tags, ",") != "music,news" {
+        t.Errorf("expected tags music and news not %v", item.Tags)
+    }
+    if item.DateModified == nil || item.DateModified.Format("15:04") != "13:00" {
+        t.Errorf("expected date modified from data-modified, got %v", item.DateModified)
+    }
+    if len(item.Attachments) != 1 || item.Attachments[0].URL != "http://yarrie.net/audio/song.mp3" || item.Attachments[0].MimeType != "audio/mpeg" {
+        t.Errorf("unexpected attachments %v", item.Attachments)
+    }
+    if len(item.Authors) != 1 || item.Authors[0].Name != "yarrie" {
+        t.Errorf("unexpected authors %v", item.Authors)
+    }
+
+    for _, item := range feed.Items {
+        if item.ID == "http://yarrie.net/microblog/#gone" {
+            t.Errorf("expected the deleted post to be left out of the items")
+        }
+    }
+    if feed.Yarrienet == nil || len(feed.Yarrienet.Deleted) != 1 {
+        t.Fatalf("expected the extension to list the deleted post, got %+v", feed.Yarrienet)
+    }
+    deleted := feed.Yarrienet.Deleted[0]
+    if deleted.ID != "http://yarrie.net/microblog/#gone" || deleted.DateDeleted == nil {
+        t.Errorf("expected the deleted post with its date, got %+v", deleted)
+    }
+}
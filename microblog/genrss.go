@@ -5,51 +5,166 @@ import (
     "yarrienet/rsshelper"
     "golang.org/x/net/html"
     h "html"
-    "fmt"
     "os"
     "strings"
     "slices"
     "time"
-    "encoding/xml"
 )
 
 type RSSMetadata struct {
     Title string
+    // Name or email address of the author. RSS only allows an email address
+    // in <author>, a name is given as dc:creator.
     Author string
     Description string
     BaseUrl string
+    // URL the feed itself is published at, used by the Atom rel="self"
+    // link and JSON Feed feed_url.
+    FeedUrl string
+    // Language of the feed, e.g. "en-GB", optional.
+    Language string
+
+    // Previous feed merged into the generated feed, which is generated from
+    // scratch when nil.
+    Merge *FeedMerge
+    // Allowlist applied to the HTML of posts, which is copied verbatim when
+    // nil.
+    Sanitize *htmlhelper.SanitizePolicy
+    // Local directory the site is published from, used to find the files of
+    // media referenced by posts. Media are not read when empty.
+    SiteRoot string
+    // URL of the site root, the public URL of SiteRoot. When empty media
+    // on the host of BaseUrl are looked up from the root of SiteRoot.
+    SiteUrl string
+
+    // Lengths in runes of titles derived from the text of posts and of
+    // summaries, the defaults when 0.
+    TitleLength int
+    SummaryLength int
+
+    // Optional RSS channel elements, omitted when empty. See
+    // rsshelper.Channel.
+    Copyright string
+    ManagingEditor string
+    WebMaster string
+    Generator string
+    Docs string
+    TTL int
+    // URL of the channel image.
+    ImageUrl string
+    Categories []string
+    SkipHours []int
+    SkipDays []string
+
+    // Put the escaped HTML of posts in the RSS <description>, which is then
+    // escaped again when encoded, rather than a plain text summary with the
+    // HTML in content:encoded. Kept for readers of the original feeds.
+    LegacyDescription bool
+
+    // Posts included in the feed, newest first. See windowPosts.
+    Window Filter
+    // RFC 5005 links of an archived feed, set by GenArchived.
+    History FeedHistory
+    // Warnings already printed, so that a run generating several feeds of
+    // the same posts prints each once. Every warning is printed when nil.
+    Warnings *FeedWarnings
+}
+
+// Length of derived titles, the default when unset.
+func (m *RSSMetadata) titleLength() int {
+    if m.TitleLength > 0 {
+        return m.TitleLength
+    }
+    return DefaultTitleLength
 }
 
+// Length of summaries, the default when unset.
+func (m *RSSMetadata) summaryLength() int {
+    if m.SummaryLength > 0 {
+        return m.SummaryLength
+    }
+    return DefaultSummaryLength
+}
+
+// Generator of feeds when not set in the metadata.
+const DefaultGenerator = "yarrienet-tools"
+
+// Documentation of the RSS format when not set in the metadata.
+const DefaultDocs = "https://www.rssboard.org/rss-specification"
+
 func getNodeClasses(n *html.Node) []string {
     return strings.Fields(htmlhelper.GetNodeAttr(n, "class"))
 }
 
+// Split the comma separated tags of a data-tags attribute, empty tags are
+// dropped.
+func parseTags(s string) []string {
+    var tags []string
+    for _, tag := range strings.Split(s, ",") {
+        if tag = strings.TrimSpace(tag); tag != "" {
+            tags = append(tags, tag)
+        }
+    }
+    return tags
+}
+
 func parseMicroblog(doc *html.Node) []Post {
+    return parseMicroblogWarn(doc, nil)
+}
+
+// Parse the posts of the microblog document, printing the warnings of
+// skipped posts with the feed warnings.
+func parseMicroblogWarn(doc *html.Node, warnings *FeedWarnings) []Post {
     var posts []Post
 
     var postId string
     var postDate time.Time
     var postNodes []*html.Node
+    var postTitle string
+    var postTags []string
+    var postModified time.Time
 
     var nestedInPostDate = false
 
     htmlhelper.WalkHtmlDoc(doc, func (wn *htmlhelper.NodeWrapper, e htmlhelper.WalkEvent) bool {
-        if slices.Contains(wn.Classes, "post") {
+        if slices.Contains(wn.Classes, "post") && slices.Contains(wn.Classes, "deleted") {
+            // tombstone of a deleted post, has no content to walk
+            if e == htmlhelper.WalkEnter {
+                dateDeleted, _ := time.Parse(time.RFC3339, htmlhelper.GetNodeAttr(wn.Node, "data-deleted"))
+                dateModified, _ := time.Parse(time.RFC3339, htmlhelper.GetNodeAttr(wn.Node, "data-modified"))
+                posts = append(posts, Post{
+                    ID: wn.ID,
+                    DateModified: dateModified,
+                    Deleted: true,
+                    DateDeleted: dateDeleted,
+                })
+            }
+            return false
+        } else if slices.Contains(wn.Classes, "post") {
             if e == htmlhelper.WalkEnter {
                 postId = wn.ID
+                postTitle = htmlhelper.GetNodeAttr(wn.Node, "data-title")
+                postTags = parseTags(htmlhelper.GetNodeAttr(wn.Node, "data-tags"))
+                postModified, _ = time.Parse(time.RFC3339, htmlhelper.GetNodeAttr(wn.Node, "data-modified"))
             } else {
                 if !postDate.IsZero() {
                     posts = append(posts, Post{
                         ID: postId,
                         DatePosted: postDate,
                         Nodes: postNodes,
+                        Title: postTitle,
+                        Tags: mergeTags(postTags, findTagLinks(postNodes)),
+                        DateModified: postModified,
                     })
                 } else {
-                    fmt.Printf("warning, post %s is missing date, skipping\n", postId)
+                    warnings.warnf("post %s is missing date, skipping", postId)
                 }
                 postId = ""
                 postDate = time.Time{}
                 postNodes = nil
+                postTitle = ""
+                postTags = nil
+                postModified = time.Time{}
                 return false
             }
         } else if postId != "" {
@@ -74,52 +189,142 @@ func parseMicroblog(doc *html.Node) []Post {
 }
 
 func postToRssItem(post Post, metadata *RSSMetadata) (*rsshelper.Item, error) {
-    var b strings.Builder
-    for _, node := range post.Nodes {
-        if node.Type != html.ElementNode {
-            continue
-        }
-        err := html.Render(&b, node)
-        // render nodes
-        if err != nil {
-            return nil, err
-        }
+    fp, err := newFeedPost(post, metadata)
+    if err != nil {
+        return nil, err
     }
-    rendered := b.String()
-    description := h.EscapeString(rendered)
-
     // assemble item
-    link := fmt.Sprintf("%s#%s", metadata.BaseUrl, post.ID)
-    return &rsshelper.Item{
-        ID: link,
-        Author: metadata.Author,
-        Link: link,
-        Description: description,
-        PubDate: post.DatePosted,
-    }, nil
+    item := &rsshelper.Item{
+        GUID: &rsshelper.GUID{Value: fp.ID},
+        Title: post.DerivedTitle(metadata.titleLength()),
+        Link: fp.Link,
+        PubDate: fp.Published,
+    }
+    if fp.ID != fp.Link {
+        // a merged guid of a renamed post no longer links to it
+        isPermaLink := false
+        item.GUID.IsPermaLink = &isPermaLink
+    }
+    if !fp.Updated.IsZero() {
+        updated := fp.Updated
+        item.Updated = &updated
+    }
+    if metadata.LegacyDescription {
+        item.Description = h.EscapeString(fp.HTML)
+    } else {
+        item.Description = post.Summary(metadata.summaryLength())
+        item.ContentEncoded = &rsshelper.CDATA{Text: fp.HTML}
+    }
+    if strings.Contains(metadata.Author, "@") {
+        item.Author = metadata.Author
+    } else {
+        item.Creator = metadata.Author
+    }
+    for _, tag := range post.Tags {
+        item.Categories = append(item.Categories, rsshelper.Category{Value: tag})
+    }
+    item.Enclosure = rssEnclosure(fp.Media)
+    item.MediaContents = rssMediaContents(fp.Media)
+    return item, nil
 }
 
+// Fill the optional channel elements from the metadata.
+func rssChannel(metadata *RSSMetadata) rsshelper.Channel {
+    channel := rsshelper.Channel{
+        Title: metadata.Title,
+        Link: metadata.BaseUrl,
+        Description: metadata.Description,
+        Language: metadata.Language,
+        Copyright: metadata.Copyright,
+        ManagingEditor: metadata.ManagingEditor,
+        WebMaster: metadata.WebMaster,
+        Generator: metadata.Generator,
+        Docs: metadata.Docs,
+        TTL: metadata.TTL,
+    }
+    if len(metadata.SkipHours) > 0 {
+        channel.SkipHours = &rsshelper.SkipHours{Hours: metadata.SkipHours}
+    }
+    if len(metadata.SkipDays) > 0 {
+        channel.SkipDays = &rsshelper.SkipDays{Days: metadata.SkipDays}
+    }
+    if channel.Generator == "" {
+        channel.Generator = DefaultGenerator
+    }
+    if channel.Docs == "" {
+        channel.Docs = DefaultDocs
+    }
+    if metadata.FeedUrl != "" {
+        channel.AtomLinks = append(channel.AtomLinks, rsshelper.AtomLink{
+            Href: metadata.FeedUrl,
+            Rel: "self",
+            Type: "application/rss+xml",
+        })
+    }
+    for _, link := range historyLinks(metadata.History) {
+        link.Type = "application/rss+xml"
+        channel.AtomLinks = append(channel.AtomLinks, link)
+    }
+    if metadata.History.archive() {
+        channel.Archive = &rsshelper.HistoryArchive{}
+    }
+    if metadata.ImageUrl != "" {
+        // the image title and link must match the channel
+        channel.Image = &rsshelper.Image{
+            URL: metadata.ImageUrl,
+            Title: metadata.Title,
+            Link: metadata.BaseUrl,
+        }
+    }
+    for _, category := range metadata.Categories {
+        channel.Categories = append(channel.Categories, rsshelper.Category{Value: category})
+    }
+    return channel
+}
+
+// Generate an RSS 2.0 feed from the microblog document, with the posts of the
+// window newest first. The channel is
+// published at the newest post and built at the newest change to a post, so
+// that the output only changes with the document.
 func GenRss(doc *html.Node, metadata *RSSMetadata) (string, error) {
-    posts := parseMicroblog(doc)
-    var items []rsshelper.Item
+    return genRss(feedPosts(doc, metadata), metadata)
+}
+
+// Generate an RSS 2.0 feed of the posts, see GenRss.
+func genRss(posts []Post, metadata *RSSMetadata) (string, error) {
+    channel := rssChannel(metadata)
     for _, post := range posts {
+        // deleted posts are left out of rss as it cannot represent them,
+        // though the deletion is a change to the channel
+        if post.Deleted {
+            if post.DateDeleted.After(channel.LastBuildDate) {
+                channel.LastBuildDate = post.DateDeleted
+            }
+            continue
+        }
         item, err := postToRssItem(post, metadata)
         if err != nil {
             return "", err
         }
-        items = append(items, *item)
+        channel.Items = append(channel.Items, *item)
+
+        if item.PubDate.After(channel.PubDate) {
+            channel.PubDate = item.PubDate
+        }
+        lastChange := item.PubDate
+        if item.Updated != nil && item.Updated.After(lastChange) {
+            lastChange = *item.Updated
+        }
+        if lastChange.After(channel.LastBuildDate) {
+            channel.LastBuildDate = lastChange
+        }
     }
 
     rssData := rsshelper.RSS{
         Version: "2.0",
-        Channel: rsshelper.Channel{
-            Title: metadata.Title,
-            Link: metadata.BaseUrl,
-            Description: metadata.Description,
-            Items: items,
-        },
+        Channel: channel,
     }
-    data, err := xml.MarshalIndent(rssData, "", "    ")
+    data, err := rsshelper.Encode(&rssData)
     if err != nil {
         return "", err
     }
@@ -0,0 +1,169 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "golang.org/x/net/html"
+    "encoding/xml"
+    "strings"
+    "testing"
+)
+
+// Testing that GenRss fills the channel from the metadata and derives its
+// dates from the posts rather than the current time.
+func TestGenRssChannel(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(exampleMicroblog))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        Author: "yarrie",
+        Description: "yarrie's microblog",
+        BaseUrl: "http://yarrie.net/microblog",
+        FeedUrl: "http://yarrie.net/microblog/rss.xml",
+        Language: "en-GB",
+        TTL: 60,
+        ImageUrl: "http://yarrie.net/icon.png",
+        Categories: []string{"personal"},
+    }
+    s, err := GenRss(doc, metadata)
+    if err != nil {
+        t.Fatalf("failed to generate rss: %s", err)
+    }
+    again, err := GenRss(doc, metadata)
+    if err != nil || again != s {
+        t.Errorf("expected generating twice to produce the same output")
+    }
+
+    expected := []string{
+        `<?xml version="1.0" encoding="UTF-8"?>`,
+        `<pubDate>Mon, 14 Apr 2025 12:26:44 +0100</pubDate>`,
+        // the deletion is the newest change
+        `<lastBuildDate>Tue, 15 Apr 2025 08:00:00 +0000</lastBuildDate>`,
+        `<language>en-GB</language>`,
+        `<generator>yarrienet-tools</generator>`,
+        `<ttl>60</ttl>`,
+        `<category>personal</category>`,
+        `<url>http://yarrie.net/icon.png</url>`,
+        `<atom:link href="http://yarrie.net/microblog/rss.xml" rel="self"`,
+        `<dc:creator>yarrie</dc:creator>`,
+        `<category>music</category>`,
+        `<guid>http://yarrie.net/microblog#second</guid>`,
+    }
+    for _, e := range expected {
+        if !strings.Contains(s, e) {
+            t.Errorf("expected rss to contain '%s':\n%s", e, s)
+        }
+    }
+    // a name is not a valid rss author
+    if strings.Contains(s, "<author>") {
+        t.Errorf("expected no <author> for an author name")
+    }
+    // deleted posts are left out
+    if strings.Contains(s, "#gone") {
+        t.Errorf("expected deleted post to be left out")
+    }
+}
+
+// Decoded item of a generated feed. Decoded with a local struct rather than
+// rsshelper.Item so that only the content is compared.
+type decodedRssItem struct {
+    Description string `xml:"description"`
+    ContentEncoded *string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+}
+
+// Decode the items of a generated feed.
+func decodeRssItems(t *testing.T, s string) []decodedRssItem {
+    var rss struct {
+        Items []decodedRssItem `xml:"channel>item"`
+    }
+    if err := xml.Unmarshal([]byte(s), &rss); err != nil {
+        t.Fatalf("failed to decode generated rss: %s", err)
+    }
+    return rss.Items
+}
+
+// Testing that the post HTML decodes exactly from content:encoded and the
+// description is plain text.
+func TestGenRssContent(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(exampleMicroblog))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    s, err := GenRss(doc, &RSSMetadata{BaseUrl: "http://yarrie.net/microblog"})
+    if err != nil {
+        t.Fatalf("failed to generate rss: %s", err)
+    }
+    items := decodeRssItems(t, s)
+    if len(items) != 2 {
+        t.Fatalf("expected 2 items, got %d", len(items))
+    }
+
+    expectedHTML := `<p>second &amp; <a href="https://example.com">newest</a></p><audio controls=""><source src="http://yarrie.net/audio/song.mp3"/></audio>`
+    if items[0].ContentEncoded == nil || *items[0].ContentEncoded != expectedHTML {
+        t.Errorf("expected content:encoded to be the post html\nexpected: %s\ngot:      %v", expectedHTML, items[0].ContentEncoded)
+    }
+    if items[0].Description != "second & newest" {
+        t.Errorf("expected plain text description 'second & newest' not '%s'", items[0].Description)
+    }
+    if strings.Contains(s, "&amp;lt;") {
+        t.Errorf("expected no double escaped html in the feed")
+    }
+}
+
+// Testing that the legacy description contains the escaped post HTML and no
+// content:encoded.
+func TestGenRssLegacyDescription(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(exampleMicroblog))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    s, err := GenRss(doc, &RSSMetadata{BaseUrl: "http://yarrie.net/microblog", LegacyDescription: true})
+    if err != nil {
+        t.Fatalf("failed to generate rss: %s", err)
+    }
+    items := decodeRssItems(t, s)
+    if len(items) != 2 {
+        t.Fatalf("expected 2 items, got %d", len(items))
+    }
+    if items[1].ContentEncoded != nil {
+        t.Errorf("expected no content:encoded in legacy mode")
+    }
+    // html is escaped once more than xml decoding removes
+    if expected := "&lt;p&gt;first&lt;/p&gt;"; items[1].Description != expected {
+        t.Errorf("expected legacy description '%s' not '%s'", expected, items[1].Description)
+    }
+}
+
+// Testing that the sanitiser of the metadata is applied to content:encoded
+// without changing the document.
+func TestGenRssSanitize(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(`<div id="posts">
+    <div class="post" id="a">
+        <div class="date"><a href="#a"><time datetime="2025-04-14T12:26:44+01:00"><p>april 14, 2025</p></time></a></div>
+        <p class="note" onclick="evil()">hello</p><script>alert(1)</script>
+    </div>
+</div>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{BaseUrl: "http://yarrie.net/microblog", Sanitize: htmlhelper.NewSanitizePolicy()}
+    s, err := GenRss(doc, metadata)
+    if err != nil {
+        t.Fatalf("failed to generate rss: %s", err)
+    }
+    items := decodeRssItems(t, s)
+    expectedHTML := `<p>hello</p>`
+    if len(items) != 1 || items[0].ContentEncoded == nil || *items[0].ContentEncoded != expectedHTML {
+        t.Fatalf("expected sanitised content:encoded\nexpected: %s\ngot:      %s", expectedHTML, s)
+    }
+
+    // the document is unchanged
+    again, err := GenRss(doc, &RSSMetadata{BaseUrl: "http://yarrie.net/microblog"})
+    if err != nil {
+        t.Fatalf("failed to generate rss: %s", err)
+    }
+    if !strings.Contains(again, "onclick") {
+        t.Errorf("expected the document to be unchanged by sanitising")
+    }
+}
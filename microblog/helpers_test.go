@@ -0,0 +1,53 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "io"
+    "os"
+    "strings"
+    "testing"
+    "time"
+)
+
+// IDs of the posts joined by commas, to compare lists of posts in one string.
+func postIDs(posts []Post) string {
+    var s []string
+    for _, post := range posts {
+        s = append(s, post.ID)
+    }
+    return strings.Join(s, ",")
+}
+
+// IDs of the RSS items joined by commas, each the fragment of its guid on the
+// example microblog.
+func itemIDs(items []rsshelper.Item) string {
+    var s []string
+    for _, item := range items {
+        s = append(s, strings.TrimPrefix(item.GUID.Value, "http://yarrie.net/microblog#"))
+    }
+    return strings.Join(s, ",")
+}
+
+// Date in UTC on 2025-04-dd at hh:00.
+func aprilUTC(day int, hour int) time.Time {
+    return time.Date(2025, time.April, day, hour, 0, 0, 0, time.UTC)
+}
+
+// Stderr written while running f.
+func captureStderr(t *testing.T, f func()) string {
+    t.Helper()
+    r, w, err := os.Pipe()
+    if err != nil {
+        t.Fatalf("failed to create pipe: %s", err)
+    }
+    stderr := os.Stderr
+    os.Stderr = w
+    defer func() { os.Stderr = stderr }()
+    f()
+    w.Close()
+    data, err := io.ReadAll(r)
+    if err != nil {
+        t.Fatalf("failed to read stderr: %s", err)
+    }
+    return string(data)
+}
@@ -0,0 +1,142 @@
+package microblog
+
+import (
+    "crypto/rand"
+    "encoding/base32"
+    "fmt"
+    "strings"
+    "time"
+    "unicode"
+    "unicode/utf8"
+)
+
+// IDStrategy names a method of generating the ID of a new post.
+type IDStrategy string
+const (
+    // Minute resolution timestamp of the post, e.g. "20251018-1432".
+    IDTimestamp IDStrategy = "timestamp"
+    // Short random lowercase base32 string, e.g. "k3j9x2qa".
+    IDRandom IDStrategy = "random"
+    // Slug derived from the post title or body text, e.g. "my-first-post".
+    // Falls back to the timestamp strategy when the text contains no usable
+    // characters.
+    IDSlug IDStrategy = "slug"
+)
+
+// Strategy used when none is configured.
+const DefaultIDStrategy = IDTimestamp
+
+// Maximum length of a generated slug, cut on a word boundary.
+const maxSlugLength = 48
+
+// Generates a post ID from the post datetime and text (e.g. title). The
+// generated ID does not need to be unique, uniqueness is handled by the caller.
+type idGenerator func(datetime time.Time, text string) (string, error)
+
+// Supported ID strategies and their generator.
+var idGenerators = map[IDStrategy]idGenerator{
+    IDTimestamp: timestampID,
+    IDRandom: randomID,
+    IDSlug: slugID,
+}
+
+// Parse and validate an ID strategy name. An empty string results in the
+// default strategy. Returns an error for unknown strategies.
+func ParseIDStrategy(s string) (IDStrategy, error) {
+    if s == "" {
+        return DefaultIDStrategy, nil
+    }
+    strategy := IDStrategy(s)
+    if _, ok := idGenerators[strategy]; !ok {
+        return "", fmt.Errorf("unknown id strategy '%s' (expected timestamp, random or slug)", s)
+    }
+    return strategy, nil
+}
+
+func timestampID(datetime time.Time, text string) (string, error) {
+    return datetime.Format("20060102-1504"), nil
+}
+
+// 40 random bits encode to exactly 8 base32 characters without padding.
+var idEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+func randomID(datetime time.Time, text string) (string, error) {
+    b := make([]byte, 5)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return idEncoding.EncodeToString(b), nil
+}
+
+func slugID(datetime time.Time, text string) (string, error) {
+    slug := slugify(text)
+    if slug == "" {
+        return timestampID(datetime, text)
+    }
+    return slug, nil
+}
+
+// Reduce text to lowercase letters and digits separated by single hyphens,
+// cut to maxSlugLength runes on a word boundary. Apostrophes are dropped
+// rather than separating words, e.g. "it's" becomes "its".
+func slugify(text string) string {
+    var words []string
+    var sb strings.Builder
+    for _, r := range strings.ToLower(text) {
+        if unicode.IsLetter(r) || unicode.IsDigit(r) {
+            sb.WriteRune(r)
+            continue
+        }
+        if r == '\'' || r == '’' {
+            continue
+        }
+        // any other character separates words
+        if sb.Len() > 0 {
+            words = append(words, sb.String())
+            sb.Reset()
+        }
+    }
+    if sb.Len() > 0 {
+        words = append(words, sb.String())
+    }
+
+    var slug string
+    for _, w := range words {
+        next := w
+        if slug != "" {
+            next = slug + "-" + w
+        }
+        if utf8.RuneCountInString(next) > maxSlugLength {
+            if slug == "" {
+                // a single word longer than the limit is cut mid-word
+                slug = string([]rune(w)[:maxSlugLength])
+            }
+            break
+        }
+        slug = next
+    }
+    return slug
+}
+
+// Generate an ID with the strategy that does not collide with any of the
+// existing IDs. Collisions are resolved by appending an incrementing suffix,
+// e.g. "20251018-1432-2".
+func generateID(strategy IDStrategy, datetime time.Time, text string, existing map[string]bool) (string, error) {
+    gen, ok := idGenerators[strategy]
+    if !ok {
+        return "", fmt.Errorf("unknown id strategy '%s'", strategy)
+    }
+    id, err := gen(datetime, text)
+    if err != nil {
+        return "", err
+    }
+    if !existing[id] {
+        return id, nil
+    }
+    for i := 2; ; i++ {
+        candidate := fmt.Sprintf("%s-%d", id, i)
+        if !existing[candidate] {
+            return candidate, nil
+        }
+    }
+}
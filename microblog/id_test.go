@@ -0,0 +1,90 @@
+package microblog
+
+import (
+    "regexp"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Testing that slugify keeps lowercase letters and digits separated by
+// single hyphens and cuts long text on a word boundary.
+func TestSlugify(t *testing.T) {
+    cases := map[string]string{
+        "My First Post": "my-first-post",
+        "  it's   a -- test!  ": "its-a-test",
+        "Café über 2025": "café-über-2025",
+        "don’t panic": "dont-panic",
+        "!!!": "",
+        "": "",
+        // cut before the word which would exceed the length
+        strings.Repeat("word ", 12): strings.TrimSuffix(strings.Repeat("word-", 9), "-"),
+        // a single long word is cut within the word
+        strings.Repeat("a", 60): strings.Repeat("a", maxSlugLength),
+    }
+    for text, expected := range cases {
+        if got := slugify(text); got != expected {
+            t.Errorf("slugify '%s' expected '%s' not '%s'", text, expected, got)
+        }
+    }
+}
+
+// Testing that generateID resolves collisions with incrementing suffixes.
+func TestGenerateIDCollisions(t *testing.T) {
+    datetime := time.Date(2025, time.October, 18, 14, 32, 0, 0, time.UTC)
+    existing := map[string]bool{}
+    for _, expected := range []string{"20251018-1432", "20251018-1432-2", "20251018-1432-3"} {
+        id, err := generateID(IDTimestamp, datetime, "", existing)
+        if err != nil {
+            t.Fatalf("failed to generate id: %s", err)
+        }
+        if id != expected {
+            t.Errorf("expected id '%s' not '%s'", expected, id)
+        }
+        existing[id] = true
+    }
+
+    existing = map[string]bool{"hello-world": true, "hello-world-3": true}
+    if id, err := generateID(IDSlug, datetime, "Hello, World", existing); err != nil || id != "hello-world-2" {
+        t.Errorf("expected slug id 'hello-world-2' not '%s' (%v)", id, err)
+    }
+    // text without letters or digits falls back to the timestamp
+    if id, err := generateID(IDSlug, datetime, "!!!", nil); err != nil || id != "20251018-1432" {
+        t.Errorf("expected the slug strategy to fall back to the timestamp not '%s' (%v)", id, err)
+    }
+    if _, err := generateID(IDStrategy("uuid"), datetime, "", nil); err == nil {
+        t.Errorf("expected an unknown strategy to fail")
+    }
+}
+
+// Testing that the random strategy generates distinct 8 character base32
+// IDs.
+func TestGenerateIDRandom(t *testing.T) {
+    pattern := regexp.MustCompile(`^[a-z2-7]{8}$`)
+    seen := map[string]bool{}
+    for i := 0; i < 50; i++ {
+        id, err := generateID(IDRandom, time.Time{}, "", seen)
+        if err != nil {
+            t.Fatalf("failed to generate id: %s", err)
+        }
+        if !pattern.MatchString(id) {
+            t.Errorf("expected 8 lowercase base32 characters not '%s'", id)
+        }
+        if seen[id] {
+            t.Errorf("expected distinct ids, '%s' was generated twice", id)
+        }
+        seen[id] = true
+    }
+}
+
+// Testing ParseIDStrategy accepts the known strategies and defaults.
+func TestParseIDStrategy(t *testing.T) {
+    for s, expected := range map[string]IDStrategy{"": DefaultIDStrategy, "timestamp": IDTimestamp, "random": IDRandom, "slug": IDSlug} {
+        if got, err := ParseIDStrategy(s); err != nil || got != expected {
+            t.Errorf("expected '%s' to parse as %s not %s (%v)", s, expected, got, err)
+        }
+    }
+    if _, err := ParseIDStrategy("uuid"); err == nil {
+        t.Errorf("expected an unknown strategy to fail")
+    }
+}
@@ -0,0 +1,76 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "golang.org/x/net/html"
+    "os"
+    "regexp"
+    "time"
+)
+
+// Parse each post from the microblog document, in document order. Posts
+// missing a date are skipped with a warning.
+func ParsePosts(doc *html.Node) []Post {
+    return parseMicroblog(doc)
+}
+
+// Parse each post from a microblog file, see ParsePosts.
+func ParsePostsFile(f *os.File) ([]Post, error) {
+    doc, err := html.Parse(f)
+    if err != nil {
+        return nil, err
+    }
+    return parseMicroblog(doc), nil
+}
+
+// Find the post with the given ID. Returns nil when missing.
+func PostByID(posts []Post, id string) *Post {
+    for i := range posts {
+        if posts[i].ID == id {
+            return &posts[i]
+        }
+    }
+    return nil
+}
+
+// Render the post content as plain text, see htmlhelper.RenderText.
+func (p *Post) Text(footnotes bool) *htmlhelper.Text {
+    return htmlhelper.RenderText(p.Nodes, footnotes)
+}
+
+// Criteria used to select posts. Zero values are ignored.
+type Filter struct {
+    // Only posts on or after this time.
+    Since time.Time
+    // Only posts on or before this time.
+    Until time.Time
+    // Only posts whose ID or plain text matches.
+    Grep *regexp.Regexp
+    // Maximum number of posts, applied after the other criteria.
+    Limit int
+}
+
+// Select the posts matching the filter, keeping their order. Deleted posts
+// are never selected.
+func FilterPosts(posts []Post, filter Filter) []Post {
+    var selected []Post
+    for _, post := range posts {
+        if filter.Limit > 0 && len(selected) >= filter.Limit {
+            break
+        }
+        if post.Deleted {
+            continue
+        }
+        if !filter.Since.IsZero() && post.DatePosted.Before(filter.Since) {
+            continue
+        }
+        if !filter.Until.IsZero() && post.DatePosted.After(filter.Until) {
+            continue
+        }
+        if filter.Grep != nil && !filter.Grep.MatchString(post.ID) && !filter.Grep.MatchString(post.Text(false).String()) {
+            continue
+        }
+        selected = append(selected, post)
+    }
+    return selected
+}
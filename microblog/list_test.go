@@ -0,0 +1,43 @@
+package microblog
+
+import (
+    "golang.org/x/net/html"
+    "regexp"
+    "strings"
+    "testing"
+)
+
+// Testing that FilterPosts selects posts by date and by matching the ID or
+// plain text, applying the limit last and leaving deleted posts out.
+func TestFilterPosts(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(`<html><body><div id="posts">
+        <div class="post" id="third"><div class="date"><time datetime="2025-04-03T12:00:00Z"></time></div><p>about <b>cats</b></p></div>
+        <div class="post deleted" id="gone" data-deleted="2025-04-02T18:00:00Z"></div>
+        <div class="post" id="cats"><div class="date"><time datetime="2025-04-02T12:00:00Z"></time></div><p>dogs</p></div>
+        <div class="post" id="first"><div class="date"><time datetime="2025-04-01T12:00:00Z"></time></div><p>Cats again</p></div>
+    </div></body></html>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    posts := parseMicroblog(doc)
+
+    cases := []struct {
+        filter Filter
+        expected string
+    }{
+        {Filter{}, "third,cats,first"},
+        {Filter{Limit: 2}, "third,cats"},
+        {Filter{Since: aprilUTC(2, 0), Until: aprilUTC(3, 0)}, "cats"},
+        // matched against the id or the plain text
+        {Filter{Grep: regexp.MustCompile("cats")}, "third,cats"},
+        {Filter{Grep: regexp.MustCompile("(?i)cats")}, "third,cats,first"},
+        {Filter{Grep: regexp.MustCompile("(?i)cats"), Limit: 2}, "third,cats"},
+        {Filter{Grep: regexp.MustCompile("(?i)cats"), Since: aprilUTC(2, 0), Limit: 1}, "third"},
+        {Filter{Grep: regexp.MustCompile("gone")}, ""},
+    }
+    for _, c := range cases {
+        if got := postIDs(FilterPosts(posts, c.filter)); got != c.expected {
+            t.Errorf("filter %+v expected '%s' not '%s'", c.filter, c.expected, got)
+        }
+    }
+}
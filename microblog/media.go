@@ -0,0 +1,133 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "image"
+    _ "image/gif"
+    _ "image/jpeg"
+    _ "image/png"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// Find the local file of a media URL within the site root. The URL must be
+// on the site, which is the site url or otherwise the host of the base url.
+// Returns an empty path when the URL is not on the site.
+func localMediaPath(mediaUrl string, metadata *RSSMetadata) string {
+    u, err := url.Parse(mediaUrl)
+    if err != nil {
+        return ""
+    }
+    site := metadata.SiteUrl
+    if site == "" {
+        site = metadata.BaseUrl
+    }
+    siteUrl, err := url.Parse(site)
+    if err != nil || u.Host != siteUrl.Host {
+        return ""
+    }
+    sitePath := siteUrl.Path
+    if metadata.SiteUrl == "" {
+        // the base url is a page of the site, only its host is known
+        sitePath = ""
+    }
+    rel, ok := strings.CutPrefix(u.Path, strings.TrimSuffix(sitePath, "/") + "/")
+    if !ok {
+        return ""
+    }
+    return filepath.Join(metadata.SiteRoot, filepath.FromSlash(rel))
+}
+
+// Fill the size, sniffed MIME type and image dimensions of the media from its
+// local file within the site root. Prints a warning when the file does not
+// exist, the media is left unchanged.
+func statMedia(post Post, media *postMedia, metadata *RSSMetadata) {
+    localPath := localMediaPath(media.URL, metadata)
+    if localPath == "" {
+        return
+    }
+    f, err := os.Open(localPath)
+    if err != nil {
+        metadata.Warnings.warnf("post %s references %s which does not exist locally at %s", post.ID, media.URL, localPath)
+        return
+    }
+    defer f.Close()
+    info, err := f.Stat()
+    if err != nil || info.IsDir() {
+        metadata.Warnings.warnf("post %s references %s which is not a file at %s", post.ID, media.URL, localPath)
+        return
+    }
+    media.Size = info.Size()
+
+    // sniff the type from the content, generic results keep the type of the
+    // element or extension
+    head := make([]byte, 512)
+    n, _ := io.ReadFull(f, head)
+    sniffed, _, _ := strings.Cut(http.DetectContentType(head[:n]), ";")
+    if sniffed != "application/octet-stream" && !strings.HasPrefix(sniffed, "text/") {
+        media.Type = sniffed
+    }
+
+    if media.Element == "img" && (media.Width == 0 || media.Height == 0) {
+        if _, err := f.Seek(0, io.SeekStart); err == nil {
+            if config, _, err := image.DecodeConfig(f); err == nil {
+                media.Width = config.Width
+                media.Height = config.Height
+            }
+        }
+    }
+}
+
+// Enclosure of an RSS item, which can only have one. The first audio or video
+// is preferred over the first image. Media are only enclosed when their local
+// file was found, as the length is required. Returns nil when no media can be
+// enclosed.
+func rssEnclosure(media []postMedia) *rsshelper.Enclosure {
+    var enclosed *postMedia
+    for i := range media {
+        m := &media[i]
+        if m.Size == 0 || m.Type == "" {
+            continue
+        }
+        if m.Element != "img" {
+            enclosed = m
+            break
+        }
+        if enclosed == nil {
+            enclosed = m
+        }
+    }
+    if enclosed == nil {
+        return nil
+    }
+    return &rsshelper.Enclosure{
+        URL: enclosed.URL,
+        Length: enclosed.Size,
+        Type: enclosed.Type,
+    }
+}
+
+// Media RSS content of the images of a post, described by their alternative
+// text.
+func rssMediaContents(media []postMedia) []rsshelper.MediaContent {
+    var contents []rsshelper.MediaContent
+    for _, m := range media {
+        if m.Element != "img" {
+            continue
+        }
+        contents = append(contents, rsshelper.MediaContent{
+            URL: m.URL,
+            FileSize: m.Size,
+            Type: m.Type,
+            Medium: "image",
+            Width: m.Width,
+            Height: m.Height,
+            Description: m.Alt,
+        })
+    }
+    return contents
+}
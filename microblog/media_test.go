@@ -0,0 +1,114 @@
+package microblog
+
+import (
+    "golang.org/x/net/html"
+    "bytes"
+    "encoding/xml"
+    "image"
+    "image/png"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// Write a file within the site root, creating its directories.
+func writeSiteFile(t *testing.T, root string, name string, data []byte) {
+    t.Helper()
+    p := filepath.Join(root, filepath.FromSlash(name))
+    if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(p, data, 0644); err != nil {
+        t.Fatal(err)
+    }
+}
+
+// Testing that media URLs are only mapped into the site root when on the site.
+func TestLocalMediaPath(t *testing.T) {
+    metadata := &RSSMetadata{BaseUrl: "http://yarrie.net/microblog", SiteUrl: "http://yarrie.net/site/", SiteRoot: "/srv/www"}
+    tests := map[string]string{
+        "http://yarrie.net/site/img/a.png": filepath.FromSlash("/srv/www/img/a.png"),
+        "http://yarrie.net/img/a.png": "",
+        "http://example.com/site/img/a.png": "",
+    }
+    for in, expected := range tests {
+        if got := localMediaPath(in, metadata); got != expected {
+            t.Errorf("expected '%s' for %s not '%s'", expected, in, got)
+        }
+    }
+    // without a site url the host of the base url is the site root
+    metadata.SiteUrl = ""
+    if got := localMediaPath("http://yarrie.net/img/a.png", metadata); got != filepath.FromSlash("/srv/www/img/a.png") {
+        t.Errorf("unexpected path '%s' without a site url", got)
+    }
+}
+
+// Testing that RSS items enclose the audio of a post with its real size and
+// describe images with media:content, with sniffed types and dimensions.
+func TestGenRssMedia(t *testing.T) {
+    root := t.TempDir()
+    var img bytes.Buffer
+    if err := png.Encode(&img, image.NewRGBA(image.Rect(0, 0, 3, 2))); err != nil {
+        t.Fatal(err)
+    }
+    // the extension does not match the content
+    writeSiteFile(t, root, "img/cat.jpg", img.Bytes())
+    song := append([]byte("ID3"), make([]byte, 97)...)
+    writeSiteFile(t, root, "audio/song.mp3", song)
+
+    doc, err := html.Parse(strings.NewReader(`<div id="posts">
+    <div class="post" id="a">
+        <div class="date"><a href="#a"><time datetime="2025-04-14T12:26:44+01:00"><p>april 14, 2025</p></time></a></div>
+        <p>a cat</p>
+        <img src="../img/cat.jpg" alt="a small cat">
+        <img src="../img/missing.png" width="10" height="5">
+        <audio controls><source src="../audio/song.mp3"></audio>
+    </div>
+</div>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{Title: "yarrie", BaseUrl: "http://yarrie.net/microblog/", SiteRoot: root}
+    s, err := GenRss(doc, metadata)
+    if err != nil {
+        t.Fatalf("failed to generate rss: %s", err)
+    }
+
+    var decoded struct {
+        Items []struct {
+            Enclosure struct {
+                URL string `xml:"url,attr"`
+                Length int64 `xml:"length,attr"`
+                Type string `xml:"type,attr"`
+            } `xml:"enclosure"`
+            MediaContents []struct {
+                URL string `xml:"url,attr"`
+                FileSize int64 `xml:"fileSize,attr"`
+                Type string `xml:"type,attr"`
+                Width int `xml:"width,attr"`
+                Height int `xml:"height,attr"`
+                Description string `xml:"description"`
+            } `xml:"content"`
+        } `xml:"channel>item"`
+    }
+    if err := xml.Unmarshal([]byte(s), &decoded); err != nil {
+        t.Fatalf("failed to decode generated rss: %s", err)
+    }
+    item := decoded.Items[0]
+    if item.Enclosure.URL != "http://yarrie.net/audio/song.mp3" || item.Enclosure.Length != int64(len(song)) || item.Enclosure.Type != "audio/mpeg" {
+        t.Errorf("unexpected enclosure %+v", item.Enclosure)
+    }
+    if len(item.MediaContents) != 2 {
+        t.Fatalf("expected 2 media:content not %d:\n%s", len(item.MediaContents), s)
+    }
+    cat := item.MediaContents[0]
+    if cat.Type != "image/png" || cat.FileSize != int64(img.Len()) || cat.Width != 3 || cat.Height != 2 || cat.Description != "a small cat" {
+        t.Errorf("unexpected media:content %+v", cat)
+    }
+    // a missing file keeps the attributes of the element
+    missing := item.MediaContents[1]
+    if missing.FileSize != 0 || missing.Width != 10 || missing.Height != 5 || missing.Type != "image/png" {
+        t.Errorf("unexpected media:content of a missing file %+v", missing)
+    }
+}
@@ -0,0 +1,125 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "time"
+)
+
+// Previous feed merged into a generated feed so that items keep their IDs and
+// publication dates between generations. Items of the previous feed are
+// matched to posts by ID or link, otherwise by identical content so that a
+// post whose ID was renamed keeps its item rather than appearing as a new
+// one. A renamed item keeps its ID but links to the new permalink, so it is
+// still matched by link when the post is later edited.
+type FeedMerge struct {
+    // Feed previously generated, e.g. decoded by rsshelper.Decode.
+    Previous *rsshelper.Feed
+    // Time recorded as the update of items whose content changed.
+    Time time.Time
+
+    // previous items by ID, link and content, built on first use
+    byID map[string]*rsshelper.FeedItem
+    byLink map[string]*rsshelper.FeedItem
+    byContent map[string]*rsshelper.FeedItem
+    // IDs of previous items matched to a post, including the permalinks of
+    // every post so that their items are only matched by ID
+    claimed map[string]bool
+}
+
+// Create a merge of the previous feed, changes are recorded at the time.
+func NewFeedMerge(previous *rsshelper.Feed, t time.Time) *FeedMerge {
+    return &FeedMerge{Previous: previous, Time: t}
+}
+
+// HTML content of a previous item, the description of RSS feeds with the
+// escaped HTML in the description.
+func itemContent(item *rsshelper.FeedItem) string {
+    if item.ContentHTML != "" {
+        return item.ContentHTML
+    }
+    return item.Summary
+}
+
+// Index the items of the previous feed.
+func (m *FeedMerge) index() {
+    m.byID = map[string]*rsshelper.FeedItem{}
+    m.byLink = map[string]*rsshelper.FeedItem{}
+    m.byContent = map[string]*rsshelper.FeedItem{}
+    m.claimed = map[string]bool{}
+    if m.Previous == nil {
+        return
+    }
+    for i := range m.Previous.Items {
+        item := &m.Previous.Items[i]
+        if item.Deleted {
+            continue
+        }
+        m.byID[item.ID] = item
+        if item.Link != "" {
+            m.byLink[item.Link] = item
+        }
+        if content := itemContent(item); content != "" {
+            if _, ok := m.byContent[content]; !ok {
+                m.byContent[content] = item
+            }
+        }
+    }
+}
+
+// Find the previous item of a post, by ID or otherwise by link or identical
+// content among the items not claimed by another post. Returns nil for new
+// posts.
+func (m *FeedMerge) find(fp *feedPost) *rsshelper.FeedItem {
+    if item, ok := m.byID[fp.Link]; ok {
+        return item
+    }
+    if item, ok := m.byLink[fp.Link]; ok && !m.claimed[item.ID] {
+        return item
+    }
+    item, ok := m.byContent[fp.HTML]
+    if !ok || m.claimed[item.ID] {
+        return nil
+    }
+    return item
+}
+
+// Match the posts of a document to the previous items before their items are
+// generated, so that an item renamed in the document is not mistaken for
+// another post. Posts which are matched keep the ID and publication date of
+// their item.
+func (m *FeedMerge) prepare(posts []Post, metadata *RSSMetadata) {
+    m.index()
+    for _, post := range posts {
+        m.claimed[postLink(post, metadata)] = true
+    }
+}
+
+// Apply the previous item of a rendered post: its ID and publication date
+// are kept and its update time carried over, or set to the merge time when
+// the content changed. Newer modification dates of the post take
+// precedence.
+func (m *FeedMerge) apply(fp *feedPost) {
+    if m.byID == nil {
+        m.index()
+    }
+    item := m.find(fp)
+    if item == nil {
+        return
+    }
+    m.claimed[item.ID] = true
+    fp.ID = item.ID
+    if !item.Published.IsZero() {
+        fp.Published = item.Published
+    }
+    // an unchanged atom entry is updated at its publication
+    updated := item.Updated
+    if updated.Equal(item.Published) {
+        updated = time.Time{}
+    }
+    if itemContent(item) != fp.HTML {
+        updated = m.Time
+    }
+    if updated.After(fp.Updated) {
+        fp.Updated = updated
+    }
+}
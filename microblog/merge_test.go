@@ -0,0 +1,91 @@
+package microblog
+
+import (
+    "yarrienet/rsshelper"
+    "golang.org/x/net/html"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Generate an RSS feed of the document, merged with the previous feed when
+// not empty.
+func genMergedRss(t *testing.T, src string, previous string, mergeTime time.Time) string {
+    t.Helper()
+    doc, err := html.Parse(strings.NewReader(src))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{Title: "yarrie", BaseUrl: "http://yarrie.net/microblog"}
+    if previous != "" {
+        feed, err := rsshelper.Decode([]byte(previous))
+        if err != nil {
+            t.Fatalf("failed to decode previous feed: %s", err)
+        }
+        metadata.Merge = NewFeedMerge(feed, mergeTime)
+    }
+    s, err := GenRss(doc, metadata)
+    if err != nil {
+        t.Fatalf("failed to generate rss: %s", err)
+    }
+    return s
+}
+
+// Testing that merging keeps the guid and pubDate of renamed posts, also when
+// they are edited after the rename, records an update of changed posts and is
+// identical when nothing changed.
+func TestGenRssMerge(t *testing.T) {
+    mergeTime := time.Date(2025, time.May, 1, 9, 0, 0, 0, time.UTC)
+    original := genMergedRss(t, exampleMicroblog, "", time.Time{})
+    if again := genMergedRss(t, exampleMicroblog, original, mergeTime); again != original {
+        t.Errorf("expected an unchanged merge to be identical\nexpected: %s\ngot:      %s", original, again)
+    }
+
+    // rename first and change the content of second
+    changed := strings.ReplaceAll(exampleMicroblog, `"first"`, `"renamed"`)
+    changed = strings.Replace(changed, "newest", "latest", 1)
+    merged := genMergedRss(t, changed, original, mergeTime)
+    feed, err := rsshelper.Decode([]byte(merged))
+    if err != nil {
+        t.Fatalf("failed to decode merged feed: %s", err)
+    }
+    if len(feed.Items) != 2 {
+        t.Fatalf("expected 2 items not %d", len(feed.Items))
+    }
+    second, renamed := feed.Items[0], feed.Items[1]
+    if !second.Updated.Equal(mergeTime) {
+        t.Errorf("expected the changed post to be updated at %s not %s", mergeTime, second.Updated)
+    }
+    if renamed.ID != "http://yarrie.net/microblog#first" || renamed.Link != "http://yarrie.net/microblog#renamed" {
+        t.Errorf("expected the renamed post to keep its guid, got %s linking to %s", renamed.ID, renamed.Link)
+    }
+    if !strings.Contains(merged, `<guid isPermaLink="false">http://yarrie.net/microblog#first</guid>`) {
+        t.Errorf("expected the kept guid to no longer be a permalink:\n%s", merged)
+    }
+    if !renamed.Published.Equal(time.Date(2025, time.April, 10, 16, 38, 10, 0, time.UTC)) || !renamed.Updated.IsZero() {
+        t.Errorf("expected the renamed post to keep its pubDate unchanged, got %s updated %s", renamed.Published, renamed.Updated)
+    }
+    if !feed.Updated.Equal(mergeTime) {
+        t.Errorf("expected the channel to be built at the update not %s", feed.Updated)
+    }
+
+    // the recorded update is carried over by the next merge
+    if again := genMergedRss(t, changed, merged, mergeTime.Add(time.Hour)); again != merged {
+        t.Errorf("expected merging the merged feed to be identical\nexpected: %s\ngot:      %s", merged, again)
+    }
+
+    // editing the renamed post keeps the item matched by its link
+    edited := strings.Replace(changed, "<p>first</p>", "<p>first, edited</p>", 1)
+    editTime := mergeTime.Add(2 * time.Hour)
+    feed, err = rsshelper.Decode([]byte(genMergedRss(t, edited, merged, editTime)))
+    if err != nil {
+        t.Fatalf("failed to decode merged feed: %s", err)
+    }
+    if len(feed.Items) != 2 {
+        t.Fatalf("expected 2 items after the edit not %d", len(feed.Items))
+    }
+    renamed = feed.Items[1]
+    if renamed.ID != "http://yarrie.net/microblog#first" || !renamed.Updated.Equal(editTime) {
+        t.Errorf("expected the edited post to keep its guid and be updated at %s, got %s updated %s", editTime, renamed.ID, renamed.Updated)
+    }
+}
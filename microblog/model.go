@@ -9,5 +9,19 @@ type Post struct {
     ID string
     DatePosted time.Time
     Nodes []*html.Node 
+    // Title of the post from the optional data-title attribute, see
+    // ExplicitTitle and DerivedTitle.
+    Title string
+    // Tags of the post from the comma separated data-tags attribute followed
+    // by the rel="tag" links in its content, see findTagLinks.
+    Tags []string
+    // When the post was last modified from the optional data-modified
+    // attribute, zero if unknown.
+    DateModified time.Time
+    // Post has been replaced by a tombstone, see DeletePost. Deleted posts
+    // have no date posted or nodes.
+    Deleted bool
+    // When the post was deleted, zero if unknown.
+    DateDeleted time.Time
 }
 
@@ -1,91 +1,162 @@
 package microblog
 
 import (
+    "bytes"
     "fmt"
     "yarrienet/htmlhelper"
     "golang.org/x/net/html"
-    "os"
+    "golang.org/x/net/html/atom"
     "time"
     "strings"
-    "slices"
+    "text/template"
 )
 
-// %[1]s post id
-// %[2]s iso 8601 datetime (?)
-// %[3]s formatted time
-// spacing is important and dependant on correct indentation on insertion
-const postTemplate = `
-        <div class="post" id="%[1]s">
-            <div class="date">
-                <a href="#%[1]s" class="post-link"><time datetime="%[2]s"><p>%[3]s</p></time></a>
-            </div>
-            <p></p>
-        </div>
-`
+// body used when a new post has no content
+const emptyBody = "<p></p>"
 
-var formattedMonths = []string{
-    "jan", "feb", "march", "april", "may", "june", "july", "aug", "sept", "oct", "nov", "dec",
+// Render the new post with the ID using its templates and kind, falling back
+// on the built-in templates and default kind.
+func generatePost(id string, post NewPost) (string, error) {
+    tmpl := post.Templates
+    if tmpl == nil {
+        tmpl = DefaultTemplates()
+    }
+    kind := post.Kind
+    if kind == "" {
+        kind = DefaultKind
+    }
+    body := strings.TrimSpace(post.Body)
+    if body == "" {
+        body = emptyBody
+    }
+    datetime := post.DateFormat.In(post.Datetime)
+    data := TemplateData{
+        ID: id,
+        Datetime: datetime.Format(time.RFC3339),
+        DisplayDate: post.DateFormat.Format(datetime),
+        Body: body,
+        Title: post.Title,
+        Tags: post.Tags,
+    }
+    return renderPost(tmpl, kind, data, datetime)
 }
 
-func generatePost(id string, datetime time.Time) string {
-    // Post.ID string
-    // Post.DatePosted time.Time
-    // Nodes []*html.Node
-    datetimeStr := datetime.Format(time.RFC3339)
-    formattedStr := fmt.Sprintf("%s %d, %d", formattedMonths[datetime.Month()-1], datetime.Day(), datetime.Year())
-    return fmt.Sprintf(postTemplate, id, datetimeStr, formattedStr)
+// Options describing a new post to be inserted.
+type NewPost struct {
+    // Date and time of the post.
+    Datetime time.Time
+    // Optional title of the post, used by the slug ID strategy and
+    // templates.
+    Title string
+    // Optional tags of the post.
+    Tags []string
+    // Optional HTML content of the post, an empty paragraph is inserted when
+    // empty.
+    Body string
+    // Strategy used to generate the post ID, defaults to DefaultIDStrategy
+    // when empty.
+    IDStrategy IDStrategy
+    // Templates used to render the post, defaults to DefaultTemplates when
+    // nil.
+    Templates *template.Template
+    // Kind of post, the name of the template used. Defaults to DefaultKind
+    // when empty.
+    Kind string
+    // Format of the visible date and time zone of the datetime, the zero
+    // value is used when nil.
+    DateFormat *DateFormat
 }
 
-func InsertNewPost(doc *html.Node, datetime time.Time) error {
-    var err error = nil
-    htmlhelper.WalkHtmlDoc(doc, func (wn *htmlhelper.NodeWrapper, e htmlhelper.WalkEvent) bool {
-        // if err is present from a previous loop frame then exit
-        if err != nil {
-            return false
-        }
-        if e != htmlhelper.WalkEnter || wn.ID != "posts" {
-            return true
-        }
-        // have found the posts div
-        var postsDiv *html.Node = wn.Node
-
-        postStr := generatePost("exampleid", datetime)
-
-        fragment, err := html.ParseFragment(strings.NewReader(postStr), postsDiv)
-        if err != nil {
-            return false
-        }
-        for _, n := range slices.Backward(fragment) {
-            postsDiv.InsertBefore(n, wn.Node.FirstChild)
+// Collect the ID of every element in the source. Element IDs are checked
+// rather than only .post IDs as any collision would break the post link.
+func collectIDs(elements []*htmlhelper.SourceElement) map[string]bool {
+    ids := make(map[string]bool)
+    for _, e := range elements {
+        if e.ID != "" {
+            ids[e.ID] = true
         }
+    }
+    return ids
+}
 
-        return false
+// The text used to generate a post ID, the title or otherwise the plain text
+// of the body.
+func (p *NewPost) idText() string {
+    if p.Title != "" || p.Body == "" {
+        return p.Title
+    }
+    nodes, err := html.ParseFragment(strings.NewReader(p.Body), &html.Node{
+        Type: html.ElementNode,
+        Data: "div",
+        DataAtom: atom.Div,
     })
     if err != nil {
-        return err
+        return ""
     }
-    return nil
+    return htmlhelper.RenderText(nodes, false).String()
 }
 
-// The function inserts an empty post element as the first child in #posts.
-// Expects a file descriptor that can read and write to a file. WARNING:
-// will truncate all contents of the file with the newly rendered document.
-func InsertNewPostFile(f *os.File, datetime time.Time) error {
-    doc, err := html.Parse(f)
+// The function inserts a post element as the first child in #posts, after
+// any comment on the line of its start tag, with a unique ID generated by the
+// post's ID strategy. The post is spliced into the HTML source so that the
+// rest of the source is left byte for byte unchanged. The body of the post must have balanced tags. Returns the new
+// source and the ID of the new post, an error on failure or when #posts is
+// missing.
+func InsertNewPost(src []byte, post NewPost) ([]byte, string, error) {
+    if strings.TrimSpace(post.Body) != "" {
+        if err := htmlhelper.CheckBalanced(post.Body); err != nil {
+            return nil, "", fmt.Errorf("post body is invalid: %s", err)
+        }
+    }
+    elements, err := htmlhelper.IndexSource(src)
     if err != nil {
-        return err
+        return nil, "", err
     }
-    err = InsertNewPost(doc, datetime)
+    postsDiv := htmlhelper.FindSourceElement(elements, func(e *htmlhelper.SourceElement) bool {
+        return e.ID == "posts"
+    })
+    if postsDiv == nil {
+        return nil, "", fmt.Errorf("missing #posts element")
+    }
+
+    strategy := post.IDStrategy
+    if strategy == "" {
+        strategy = DefaultIDStrategy
+    }
+    id, err := generateID(strategy, post.Datetime, post.idText(), collectIDs(elements))
     if err != nil {
-        return err
+        return nil, "", err
     }
-    
-    f.Truncate(0)
-    f.Seek(0, 0)
-    err = html.Render(f, doc)
+
+    postStr, err := generatePost(id, post)
     if err != nil {
-        return err
+        return nil, "", err
     }
-    return nil
+    at := postInsertPosition(src, postsDiv.ContentStart)
+    return htmlhelper.Splice(src, at, at, postStr), id, nil
 }
 
+// Position the new post is inserted at: the end of the line of the start tag
+// of the posts div when only spaces and comments follow the tag on it, so that
+// they stay on that line above the new post, otherwise directly after the
+// start tag.
+func postInsertPosition(src []byte, contentStart int) int {
+    pos := contentStart
+    for pos < len(src) {
+        switch {
+        case src[pos] == ' ' || src[pos] == '\t':
+            pos++
+        case bytes.HasPrefix(src[pos:], []byte("<!--")):
+            end := bytes.Index(src[pos+4:], []byte("-->"))
+            if end == -1 {
+                return contentStart
+            }
+            pos += 4 + end + 3
+        case src[pos] == '\r' || src[pos] == '\n':
+            return pos
+        default:
+            return contentStart
+        }
+    }
+    return contentStart
+}
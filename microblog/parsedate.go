@@ -0,0 +1,155 @@
+package microblog
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Layouts of absolute dates accepted by ParseDate without a time zone, read
+// in the given location.
+var dateLayouts = []string{
+    "2006-01-02-15:04:05",
+    "2006-01-02-15-04-05",
+    "2006-01-02T15:04:05",
+    "2006-01-02T15:04",
+    "2006-01-02 15:04:05",
+    "2006-01-02 15:04",
+    "2006-01-02",
+}
+
+// Layouts of times of day accepted by ParseDate, alone or following a day
+// name.
+var timeLayouts = []string{
+    "15:04:05",
+    "15:04",
+}
+
+// Days relative to today by name.
+var relativeDays = map[string]int{
+    "today": 0,
+    "yesterday": -1,
+    "tomorrow": 1,
+}
+
+// Description of the forms accepted by ParseDate, used in errors and usage.
+const DateForms = `RFC3339 (2006-01-02T15:04:05+01:00), date and time (2006-01-02 15:04[:05]),
+date only (2006-01-02), time only for today (15:04[:05]), now, today, yesterday or tomorrow
+optionally followed by a time (yesterday 18:30), or an offset from now (-2h, +30m, -1d12h, -1w)`
+
+// Parse a date in one of the forms described by DateForms. Dates without a
+// time zone are read in the location, times without a date are on the day of
+// now within the location and offsets are relative to now. Returns the
+// parsed time, an error listing the accepted forms on failure.
+func ParseDate(s string, now time.Time, loc *time.Location) (time.Time, error) {
+    if loc == nil {
+        loc = time.Local
+    }
+    now = now.In(loc)
+    s = strings.TrimSpace(s)
+
+    if t, err := time.Parse(time.RFC3339, s); err == nil {
+        return t, nil
+    }
+    for _, layout := range dateLayouts {
+        if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+            return t, nil
+        }
+    }
+    if s == "now" {
+        return now, nil
+    }
+    if t, ok := parseRelativeDay(s, now); ok {
+        return t, nil
+    }
+    if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+        if t, ok := parseOffset(s, now); ok {
+            return t, nil
+        }
+    }
+    return time.Time{}, fmt.Errorf("invalid date '%s', expected one of:\n%s", s, DateForms)
+}
+
+// Parse a date as ParseDate for the end of a range, e.g. an until date. A
+// date without a time (2006-01-02, today, yesterday or tomorrow) is the last
+// instant of that day rather than its start, so that the whole day is
+// included. Returns the parsed time, an error on failure.
+func ParseDateEnd(s string, now time.Time, loc *time.Location) (time.Time, error) {
+    t, err := ParseDate(s, now, loc)
+    if err != nil {
+        return t, err
+    }
+    s = strings.TrimSpace(s)
+    _, dateErr := time.Parse("2006-01-02", s)
+    if _, ok := relativeDays[s]; ok || dateErr == nil {
+        // calendar day so that days of daylight saving changes are whole
+        return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+    }
+    return t, nil
+}
+
+// Parse a time of day, optionally preceded by a day name such as
+// "yesterday", on the day relative to now. A day name alone is midnight.
+func parseRelativeDay(s string, now time.Time) (time.Time, bool) {
+    var offset = 0
+    var clock = s
+    name, rest, _ := strings.Cut(s, " ")
+    if o, ok := relativeDays[name]; ok {
+        offset = o
+        clock = strings.TrimSpace(rest)
+        if clock == "" {
+            return time.Date(now.Year(), now.Month(), now.Day() + offset, 0, 0, 0, 0, now.Location()), true
+        }
+    }
+    for _, layout := range timeLayouts {
+        if t, err := time.Parse(layout, clock); err == nil {
+            return time.Date(now.Year(), now.Month(), now.Day() + offset, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), true
+        }
+    }
+    return time.Time{}, false
+}
+
+// Parse a signed offset from now made of numbers with the units w, d, h, m
+// or s, e.g. "-1d12h". Weeks and days are calendar days so that the time of
+// day is kept across daylight saving changes.
+func parseOffset(s string, now time.Time) (time.Time, bool) {
+    var sign = 1
+    if s[0] == '-' {
+        sign = -1
+    }
+    s = s[1:]
+    if s == "" {
+        return time.Time{}, false
+    }
+
+    var days = 0
+    var duration time.Duration
+    for s != "" {
+        i := 0
+        for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {}
+        if i == 0 || i == len(s) {
+            return time.Time{}, false
+        }
+        n, err := strconv.Atoi(s[:i])
+        if err != nil {
+            return time.Time{}, false
+        }
+        switch s[i] {
+        case 'w':
+            days += n * 7
+        case 'd':
+            days += n
+        case 'h':
+            duration += time.Duration(n) * time.Hour
+        case 'm':
+            duration += time.Duration(n) * time.Minute
+        case 's':
+            duration += time.Duration(n) * time.Second
+        default:
+            return time.Time{}, false
+        }
+        s = s[i+1:]
+    }
+    return now.AddDate(0, 0, sign * days).Add(time.Duration(sign) * duration), true
+}
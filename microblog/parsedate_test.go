@@ -0,0 +1,94 @@
+package microblog
+
+import (
+    "testing"
+    "time"
+)
+
+// Testing ParseDate with absolute, time only, named day and offset forms read
+// in a fixed location.
+func TestParseDate(t *testing.T) {
+    loc, err := time.LoadLocation("Europe/London")
+    if err != nil {
+        t.Fatalf("failed to load location: %s", err)
+    }
+    // 2025-03-30 is the start of British Summer Time at 01:00 UTC
+    now := time.Date(2025, time.March, 30, 14, 0, 0, 0, loc)
+
+    cases := []struct {
+        input string
+        expected time.Time
+    }{
+        {"2025-04-10T17:38:10+02:00", time.Date(2025, time.April, 10, 15, 38, 10, 0, time.UTC)},
+        {"2025-04-10-17:38:10", time.Date(2025, time.April, 10, 17, 38, 10, 0, loc)},
+        {"2025-04-10-17-38-10", time.Date(2025, time.April, 10, 17, 38, 10, 0, loc)},
+        {"2025-04-10 17:38", time.Date(2025, time.April, 10, 17, 38, 0, 0, loc)},
+        {"2025-01-10", time.Date(2025, time.January, 10, 0, 0, 0, 0, loc)},
+        {"09:15", time.Date(2025, time.March, 30, 9, 15, 0, 0, loc)},
+        {"now", now},
+        {"today", time.Date(2025, time.March, 30, 0, 0, 0, 0, loc)},
+        {"yesterday 18:30", time.Date(2025, time.March, 29, 18, 30, 0, 0, loc)},
+        {"tomorrow 08:00:05", time.Date(2025, time.March, 31, 8, 0, 5, 0, loc)},
+        {"-2h", now.Add(-2 * time.Hour)},
+        {"+30m", now.Add(30 * time.Minute)},
+        // calendar days keep the time of day across the clock change
+        {"-1d", time.Date(2025, time.March, 29, 14, 0, 0, 0, loc)},
+        {"-1w2d3h", time.Date(2025, time.March, 21, 11, 0, 0, 0, loc)},
+    }
+    for _, c := range cases {
+        got, err := ParseDate(c.input, now, loc)
+        if err != nil {
+            t.Errorf("failed to parse '%s': %s", c.input, err)
+            continue
+        }
+        if !got.Equal(c.expected) {
+            t.Errorf("parsing '%s' expected %s not %s", c.input, c.expected, got)
+        }
+    }
+
+    for _, input := range []string{"", "last week", "2025-13-01", "-2", "-h", "+2y", "noon"} {
+        if got, err := ParseDate(input, now, loc); err == nil {
+            t.Errorf("expected '%s' to fail, parsed as %s", input, got)
+        }
+    }
+}
+
+// Testing that ParseDateEnd reads dates without a time as the end of the day
+// and other dates as ParseDate does.
+func TestParseDateEnd(t *testing.T) {
+    loc, err := time.LoadLocation("Europe/London")
+    if err != nil {
+        t.Fatalf("failed to load location: %s", err)
+    }
+    now := time.Date(2025, time.March, 30, 14, 0, 0, 0, loc)
+    endOf := func(year int, month time.Month, day int) time.Time {
+        return time.Date(year, month, day, 23, 59, 59, 999999999, loc)
+    }
+
+    cases := []struct {
+        input string
+        expected time.Time
+    }{
+        {"2025-01-10", endOf(2025, time.January, 10)},
+        // the day of the clock change is 23 hours long
+        {"today", endOf(2025, time.March, 30)},
+        {" yesterday ", endOf(2025, time.March, 29)},
+        {"tomorrow", endOf(2025, time.March, 31)},
+        {"2025-01-10 17:38", time.Date(2025, time.January, 10, 17, 38, 0, 0, loc)},
+        {"yesterday 18:30", time.Date(2025, time.March, 29, 18, 30, 0, 0, loc)},
+        {"-2h", now.Add(-2 * time.Hour)},
+    }
+    for _, c := range cases {
+        got, err := ParseDateEnd(c.input, now, loc)
+        if err != nil {
+            t.Errorf("failed to parse '%s': %s", c.input, err)
+            continue
+        }
+        if !got.Equal(c.expected) {
+            t.Errorf("parsing '%s' expected %s not %s", c.input, c.expected, got)
+        }
+    }
+    if got, err := ParseDateEnd("noon", now, loc); err == nil {
+        t.Errorf("expected 'noon' to fail, parsed as %s", got)
+    }
+}
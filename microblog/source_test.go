@@ -0,0 +1,109 @@
+package microblog
+
+import (
+    "os"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Read the hand formatted microblog of the test data.
+func readHandFormatted(t *testing.T) string {
+    t.Helper()
+    data, err := os.ReadFile("testdata/index.html")
+    if err != nil {
+        t.Fatalf("failed to read test microblog: %s", err)
+    }
+    return string(data)
+}
+
+// Check that the changed source is the original source with the text from
+// start to end replaced, byte for byte. Returns the replacement text.
+func replaced(t *testing.T, original string, changed string, start int, end int) string {
+    t.Helper()
+    if start < 0 || end < start {
+        t.Fatalf("invalid expected change from %d to %d", start, end)
+    }
+    if !strings.HasPrefix(changed, original[:start]) || !strings.HasSuffix(changed, original[end:]) || len(changed) < len(original) - (end - start) {
+        t.Fatalf("expected only %q to be replaced\noriginal: %s\nchanged:  %s", original[start:end], original, changed)
+    }
+    return changed[start:len(changed)-(len(original)-end)]
+}
+
+// Testing that InsertNewPost only inserts the post at the end of the line of
+// the start tag of #posts, keeping the comment on that line.
+func TestInsertNewPostUnchanged(t *testing.T) {
+    src := readHandFormatted(t)
+    out, id, err := InsertNewPost([]byte(src), NewPost{
+        Datetime: time.Date(2025, time.April, 20, 9, 30, 0, 0, time.UTC),
+        Body: "<p>third</p>",
+    })
+    if err != nil {
+        t.Fatalf("failed to insert post: %s", err)
+    }
+    comment := "<!-- newest first, keep this comment on the line -->"
+    start := strings.Index(src, `<div   id="posts"  >` + comment) + len(`<div   id="posts"  >` + comment)
+    inserted := replaced(t, src, string(out), start, start)
+    if !strings.Contains(inserted, `id="` + id + `"`) || !strings.Contains(inserted, "<p>third</p>") {
+        t.Errorf("expected the inserted post %s with its body, got %s", id, inserted)
+    }
+}
+
+// Testing where the new post goes when the start tag of #posts is followed
+// on its line by spaces and comments or by the first post.
+func TestPostInsertPosition(t *testing.T) {
+    tests := map[string]string{
+        "<div id=\"posts\"> <!-- a --> <!-- b -->\n    <div class=\"post\">": "<div id=\"posts\"> <!-- a --> <!-- b -->",
+        "<div id=\"posts\"><!-- a\nb -->\r\n    <div class=\"post\">": "<div id=\"posts\"><!-- a\nb -->",
+        "<div id=\"posts\"><div class=\"post\">\n": "<div id=\"posts\">",
+        "<div id=\"posts\"><!-- a --><div class=\"post\">\n": "<div id=\"posts\">",
+        "<div id=\"posts\"><!-- unclosed\n": "<div id=\"posts\">",
+    }
+    for src, before := range tests {
+        if at := postInsertPosition([]byte(src), len(`<div id="posts">`)); src[:at] != before {
+            t.Errorf("expected the post inserted after %q in %q, not after %q", before, src, src[:at])
+        }
+    }
+}
+
+// Testing that EditPost only replaces the body of the post.
+func TestEditPostUnchanged(t *testing.T) {
+    src := readHandFormatted(t)
+    out, changed, err := EditPost([]byte(src), "first", func(body string) (string, error) {
+        return "<p>edited</p>", nil
+    })
+    if err != nil || !changed {
+        t.Fatalf("failed to edit post (changed %t): %v", changed, err)
+    }
+    start := strings.Index(src, "<p>first<br/>")
+    end := strings.Index(src, "post</p>") + len("post</p>")
+    if got := replaced(t, src, string(out), start, end); got != "<p>edited</p>" {
+        t.Errorf("expected the body to be replaced by the edit not %q", got)
+    }
+}
+
+// Testing that DeletePost only removes the post, or replaces it by a
+// tombstone.
+func TestDeletePostUnchanged(t *testing.T) {
+    src := readHandFormatted(t)
+    post := `<div class="post" id="20250414-1226" data-tags="music,  news">`
+    start := strings.Index(src, post)
+    end := strings.Index(src, "</div>\n\n  <div class=post") + len("</div>")
+
+    out, err := DeletePost([]byte(src), "20250414-1226", false, time.Time{})
+    if err != nil {
+        t.Fatalf("failed to delete post: %s", err)
+    }
+    // the line of the post is removed with it
+    if got := replaced(t, src, string(out), start - len("\n    "), end); got != "" {
+        t.Errorf("expected the post to be removed, %q was inserted", got)
+    }
+
+    out, err = DeletePost([]byte(src), "20250414-1226", true, aprilUTC(15, 8))
+    if err != nil {
+        t.Fatalf("failed to tombstone post: %s", err)
+    }
+    if got := replaced(t, src, string(out), start, end); got != `<div class="post deleted" id="20250414-1226" data-deleted="2025-04-15T08:00:00Z"></div>` {
+        t.Errorf("expected the post to be replaced by a tombstone not %q", got)
+    }
+}
@@ -0,0 +1,88 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "golang.org/x/net/html"
+    "strings"
+    "unicode/utf8"
+)
+
+// Length in runes of titles derived from the text of a post, when not
+// configured.
+const DefaultTitleLength = 80
+
+// Length in runes of the plain text summary of a post, when not configured.
+const DefaultSummaryLength = 280
+
+// Collapse the whitespace of s to single spaces and cut it to at most n runes
+// on a word boundary with a trailing ellipsis. A word longer than n is cut
+// within the word.
+func cutText(s string, n int) string {
+    s = strings.Join(strings.Fields(s), " ")
+    if n <= 0 || utf8.RuneCountInString(s) <= n {
+        return s
+    }
+    cut := string([]rune(s)[:n])
+    if i := strings.LastIndex(cut, " "); i > 0 {
+        cut = cut[:i]
+    }
+    return strings.TrimRight(cut, ",;:") + "…"
+}
+
+// Plain text summary of the post on a single line, cut to at most n runes on
+// a word boundary with a trailing ellipsis. Used wherever a post is
+// summarised: feed descriptions and summaries, and microblog list.
+func (p *Post) Summary(n int) string {
+    return cutText(p.Text(false).String(), n)
+}
+
+// Explicit title of the post, the data-title attribute or otherwise the text
+// of the first <h2>. Returns an empty string when the post has neither.
+func (p *Post) ExplicitTitle() string {
+    if p.Title != "" {
+        return p.Title
+    }
+    for _, node := range p.Nodes {
+        var h2 *html.Node
+        htmlhelper.WalkHtmlDoc(node, func(wn *htmlhelper.NodeWrapper, e htmlhelper.WalkEvent) bool {
+            if h2 == nil && wn.ElementType == "h2" {
+                h2 = wn.Node
+            }
+            return h2 == nil
+        })
+        if h2 != nil {
+            return cutText(htmlhelper.RenderText([]*html.Node{h2}, false).String(), 0)
+        }
+    }
+    return ""
+}
+
+// Title of the post, the explicit title when present, otherwise the first
+// sentence of its plain text cut to at most n runes on a word boundary.
+func (p *Post) DerivedTitle(n int) string {
+    if title := p.ExplicitTitle(); title != "" {
+        return title
+    }
+    return cutText(firstSentence(p.Text(false).String()), n)
+}
+
+// First sentence of the text within its first paragraph. A sentence ends at
+// '.', '!' or '?' followed by whitespace or the end of the paragraph, a
+// final full stop is dropped.
+func firstSentence(text string) string {
+    paragraph, _, _ := strings.Cut(strings.TrimSpace(text), "\n\n")
+    for i, r := range paragraph {
+        if r != '.' && r != '!' && r != '?' {
+            continue
+        }
+        next := i + utf8.RuneLen(r)
+        if next < len(paragraph) && !strings.ContainsRune(" \t\n", rune(paragraph[next])) {
+            continue
+        }
+        if r == '.' {
+            return paragraph[:i]
+        }
+        return paragraph[:next]
+    }
+    return strings.TrimSuffix(paragraph, ".")
+}
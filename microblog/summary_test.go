@@ -0,0 +1,64 @@
+package microblog
+
+import (
+    "golang.org/x/net/html"
+    "strings"
+    "testing"
+)
+
+// Parse a single post with the given attributes and content.
+func parseTestPost(t *testing.T, attrs string, content string) Post {
+    src := `<div id="posts"><div class="post" id="a"` + attrs + `><div class="date"><time datetime="2025-04-10T17:38:10+01:00"><p>april 10, 2025</p></time></div>` + content + `</div></div>`
+    doc, err := html.Parse(strings.NewReader(src))
+    if err != nil {
+        t.Fatalf("failed to parse post: %s", err)
+    }
+    posts := parseMicroblog(doc)
+    if len(posts) != 1 {
+        t.Fatalf("expected 1 post, got %d", len(posts))
+    }
+    return posts[0]
+}
+
+// Testing the title taken from data-title, an <h2> or the first sentence.
+func TestDerivedTitle(t *testing.T) {
+    cases := []struct {
+        attrs string
+        content string
+        n int
+        expected string
+    }{
+        {` data-title="Explicit &amp; title"`, `<h2>heading</h2><p>text.</p>`, 80, "Explicit & title"},
+        {``, `<p>intro</p><h2>The <em>heading</em></h2><p>text.</p>`, 80, "The heading"},
+        {``, `<p>Hello <a href="x">world</a>. Second sentence.</p>`, 80, "Hello world"},
+        {``, `<p>Is this a question? Yes.</p>`, 80, "Is this a question?"},
+        {``, `<p>Version 1.5 is out</p><p>next paragraph.</p>`, 80, "Version 1.5 is out"},
+        {``, `<p>A rather long first sentence which goes on, and on well past the limit.</p>`, 30, "A rather long first sentence…"},
+        {``, `<p>Short, cut: within punctuation</p>`, 12, "Short, cut…"},
+        {``, `<img src="x.jpg" alt="a cat">`, 80, "[img: a cat]"},
+    }
+    for _, c := range cases {
+        post := parseTestPost(t, c.attrs, c.content)
+        if title := post.DerivedTitle(c.n); title != c.expected {
+            t.Errorf("title of %s expected '%s' not '%s'", c.content, c.expected, title)
+        }
+    }
+
+    // untitled posts have no explicit title
+    post := parseTestPost(t, ``, `<p>Hello world.</p>`)
+    if title := post.ExplicitTitle(); title != "" {
+        t.Errorf("expected no explicit title not '%s'", title)
+    }
+}
+
+// Testing the summary is the whole text on one line cut on a word boundary.
+func TestSummary(t *testing.T) {
+    post := parseTestPost(t, ``, `<p>First sentence.  Second
+        sentence.</p><ul><li>one</li></ul>`)
+    if s := post.Summary(100); s != "First sentence. Second sentence. - one" {
+        t.Errorf("unexpected summary '%s'", s)
+    }
+    if s := post.Summary(25); s != "First sentence. Second…" {
+        t.Errorf("unexpected cut summary '%s'", s)
+    }
+}
@@ -0,0 +1,170 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "yarrienet/rsshelper"
+    "golang.org/x/net/html"
+    "fmt"
+    "net/url"
+    "path"
+    "slices"
+    "strings"
+)
+
+// Tags of the rel="tag" links in the nodes of a post. As in the rel-tag
+// microformat the tag of a link is the last segment of its path, e.g.
+// "music" for "/tags/music", or the text of the link when it has no path.
+func findTagLinks(nodes []*html.Node) []string {
+    var tags []string
+    for _, node := range nodes {
+        htmlhelper.WalkHtmlDoc(node, func(wn *htmlhelper.NodeWrapper, e htmlhelper.WalkEvent) bool {
+            if e != htmlhelper.WalkEnter || wn.ElementType != "a" {
+                return true
+            }
+            if !slices.Contains(strings.Fields(htmlhelper.GetNodeAttr(wn.Node, "rel")), "tag") {
+                return true
+            }
+            var tag string
+            if u, err := url.Parse(htmlhelper.GetNodeAttr(wn.Node, "href")); err == nil {
+                if segment := path.Base(strings.TrimSuffix(u.Path, "/")); segment != "." && segment != "/" {
+                    tag, _ = url.PathUnescape(segment)
+                }
+            }
+            if tag == "" {
+                tag = nodeText(wn.Node)
+            }
+            if tag = strings.TrimSpace(tag); tag != "" {
+                tags = append(tags, tag)
+            }
+            return false
+        })
+    }
+    return tags
+}
+
+// Text of the node and its descendants.
+func nodeText(node *html.Node) string {
+    if node.Type == html.TextNode {
+        return node.Data
+    }
+    var sb strings.Builder
+    for child := node.FirstChild; child != nil; child = child.NextSibling {
+        sb.WriteString(nodeText(child))
+    }
+    return sb.String()
+}
+
+// Tags without duplicates, compared by slug so that "Go" and "go" are one
+// tag, keeping the first spelling.
+func mergeTags(tags ...[]string) []string {
+    var merged []string
+    seen := map[string]bool{}
+    for _, list := range tags {
+        for _, tag := range list {
+            slug := slugify(tag)
+            if seen[slug] {
+                continue
+            }
+            seen[slug] = true
+            merged = append(merged, tag)
+        }
+    }
+    return merged
+}
+
+// Feed of the posts with one tag, see GenTagFeeds.
+type TagFeed struct {
+    // Tag as first spelt in the document.
+    Tag string
+    // Slug of the tag, unique among the tags and used to name its feed.
+    Slug string
+    // URL the feed is published at.
+    URL string
+    // Number of posts in the feed.
+    Count int
+    // Generated feed document.
+    Data string
+}
+
+// Generate a feed in the format, "rss", "atom" or "json", of the posts with
+// each tag in the microblog document, matched by the slug of the tag. The
+// window is applied to the posts of each tag and deleted posts, which have
+// no tags, are left out. Each feed is titled by the metadata title followed
+// by its tag and published at tagUrl(slug). Returns the feeds sorted by
+// slug, or an error when two tags which differ other than in case have the
+// same slug.
+func GenTagFeeds(doc *html.Node, metadata *RSSMetadata, format string, tagUrl func(slug string) string) ([]TagFeed, error) {
+    gen, err := feedGenerator(format)
+    if err != nil {
+        return nil, err
+    }
+    posts := parseFeedPosts(doc, metadata)
+
+    tagged := map[string][]Post{}
+    spelling := map[string]string{}
+    for _, post := range posts {
+        for _, tag := range post.Tags {
+            slug := slugify(tag)
+            if slug == "" {
+                metadata.Warnings.warnf("tag '%s' of post %s has no letters or digits to name its feed, skipping", tag, post.ID)
+                continue
+            }
+            // tags only differing in case are one tag, other tags with the
+            // same slug would share a feed
+            if first, ok := spelling[slug]; !ok {
+                spelling[slug] = tag
+            } else if !strings.EqualFold(first, tag) {
+                return nil, fmt.Errorf("tags '%s' and '%s' (post %s) both have the slug '%s' naming their feed, rename one of them", first, tag, post.ID, slug)
+            }
+            tagged[slug] = append(tagged[slug], post)
+        }
+    }
+
+    var feeds []TagFeed
+    for slug, tagPosts := range tagged {
+        feed := TagFeed{Tag: spelling[slug], Slug: slug, URL: tagUrl(slug)}
+        tagMetadata := *metadata
+        tagMetadata.Title = fmt.Sprintf("%s: %s", metadata.Title, feed.Tag)
+        tagMetadata.FeedUrl = feed.URL
+        tagMetadata.Categories = []string{feed.Tag}
+        tagMetadata.History = FeedHistory{}
+        window := windowPosts(tagPosts, metadata.Window)
+        feed.Count = len(window)
+        if feed.Data, err = gen(window, &tagMetadata); err != nil {
+            return nil, err
+        }
+        feeds = append(feeds, feed)
+    }
+    slices.SortFunc(feeds, func(a, b TagFeed) int {
+        return strings.Compare(a.Slug, b.Slug)
+    })
+    return feeds, nil
+}
+
+// Generate an OPML index of the tag feeds, listing each tag with the URL of
+// its feed, which can be imported by feed readers.
+func GenTagIndex(feeds []TagFeed, metadata *RSSMetadata) (string, error) {
+    opml := rsshelper.OPML{
+        Version: "2.0",
+        Head: rsshelper.OPMLHead{Title: fmt.Sprintf("%s tags", metadata.Title)},
+    }
+    for _, feed := range feeds {
+        posts := "posts"
+        if feed.Count == 1 {
+            posts = "post"
+        }
+        opml.Body.Outlines = append(opml.Body.Outlines, rsshelper.OPMLOutline{
+            Type: "rss",
+            Text: feed.Tag,
+            Title: fmt.Sprintf("%s: %s", metadata.Title, feed.Tag),
+            XMLURL: feed.URL,
+            HTMLURL: metadata.BaseUrl,
+            Description: fmt.Sprintf("%d %s tagged %s", feed.Count, posts, feed.Tag),
+        })
+    }
+    data, err := rsshelper.EncodeOPML(&opml)
+    if err != nil {
+        return "", err
+    }
+    return string(data), nil
+}
@@ -0,0 +1,161 @@
+package microblog
+
+import (
+    "yarrienet/htmlhelper"
+    "yarrienet/rsshelper"
+    "encoding/xml"
+    "golang.org/x/net/html"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+var taggedMicroblog = `<html><body><div id="posts">
+    <div class="post" id="third" data-tags="Go">
+        <div class="date"><time datetime="2025-04-16T12:00:00Z"></time></div>
+        <p>third <a rel="tag" href="/tags/go/">go</a> <a rel="nofollow tag" href="../tags/field%20recording">recording</a></p>
+    </div>
+    <div class="post deleted" id="gone" data-deleted="2025-04-15T08:00:00Z"></div>
+    <div class="post" id="second" data-tags="music, go">
+        <div class="date"><time datetime="2025-04-14T12:00:00Z"></time></div>
+        <p>second <a rel="tag" href="">Ambient</a> <a href="/tags/not-a-tag">link</a></p>
+    </div>
+    <div class="post" id="first">
+        <div class="date"><time datetime="2025-04-10T12:00:00Z"></time></div>
+        <p>first</p>
+    </div>
+</div></body></html>`
+
+// Testing that tags are read from data-tags and rel="tag" links, without
+// duplicates by slug.
+func TestPostTags(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(taggedMicroblog))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    expected := map[string][]string{
+        "third": {"Go", "field recording"},
+        "gone": nil,
+        "second": {"music", "go", "Ambient"},
+        "first": nil,
+    }
+    for _, post := range parseMicroblog(doc) {
+        if !reflect.DeepEqual(post.Tags, expected[post.ID]) {
+            t.Errorf("expected post %s to have tags %q not %q", post.ID, expected[post.ID], post.Tags)
+        }
+    }
+}
+
+// Testing that GenTagFeeds generates a feed of the posts of each tag and
+// GenTagIndex lists them.
+func TestGenTagFeeds(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(taggedMicroblog))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        Author: "yarrie",
+        Description: "yarrie's microblog",
+        BaseUrl: "http://yarrie.net/microblog",
+        FeedUrl: "http://yarrie.net/microblog/rss.xml",
+    }
+    tagUrl := func(slug string) string {
+        return "http://yarrie.net/microblog/tags/" + slug + ".xml"
+    }
+    feeds, err := GenTagFeeds(doc, metadata, "rss", tagUrl)
+    if err != nil {
+        t.Fatalf("failed to generate tag feeds: %s", err)
+    }
+
+    expected := map[string]string{
+        "ambient": "second",
+        "field-recording": "third",
+        "go": "third,second",
+        "music": "second",
+    }
+    var slugs []string
+    for _, feed := range feeds {
+        slugs = append(slugs, feed.Slug)
+        var rss rsshelper.RSS
+        if err := xml.Unmarshal([]byte(feed.Data), &rss); err != nil {
+            t.Fatalf("failed to decode feed of %s: %s", feed.Tag, err)
+        }
+        if rss.Channel.Title != "yarrie: " + feed.Tag {
+            t.Errorf("unexpected title of the feed of %s '%s'", feed.Tag, rss.Channel.Title)
+        }
+        if len(rss.Channel.AtomLinks) != 1 || rss.Channel.AtomLinks[0].Href != feed.URL || feed.URL != tagUrl(feed.Slug) {
+            t.Errorf("expected the feed of %s to link to itself at %s, got %+v", feed.Tag, feed.URL, rss.Channel.AtomLinks)
+        }
+        if ids := itemIDs(rss.Channel.Items); ids != expected[feed.Slug] || feed.Count != len(rss.Channel.Items) {
+            t.Errorf("expected the feed of %s to have posts '%s' not '%s' (count %d)", feed.Tag, expected[feed.Slug], ids, feed.Count)
+        }
+    }
+    if strings.Join(slugs, ",") != "ambient,field-recording,go,music" {
+        t.Errorf("unexpected tag feeds %v", slugs)
+    }
+
+    s, err := GenTagIndex(feeds, metadata)
+    if err != nil {
+        t.Fatalf("failed to generate tag index: %s", err)
+    }
+    var opml rsshelper.OPML
+    if err := xml.Unmarshal([]byte(s), &opml); err != nil {
+        t.Fatalf("failed to decode tag index: %s", err)
+    }
+    if len(opml.Body.Outlines) != 4 {
+        t.Fatalf("expected 4 tags in the index not %d", len(opml.Body.Outlines))
+    }
+    goTag := opml.Body.Outlines[2]
+    if goTag.Text != "Go" || goTag.XMLURL != tagUrl("go") || goTag.Description != "2 posts tagged Go" {
+        t.Errorf("unexpected outline of go %+v", goTag)
+    }
+}
+
+// Testing that the warnings of the posts of the main feed and tag feeds of
+// one run are printed once.
+func TestFeedWarningsOnce(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(`<html><body><div id="posts">
+        <div class="post" id="old" data-tags="go"><div class="date"><time datetime="2025-04-01T12:00:00Z"></time></div><p>old<script>x</script></p></div>
+        <div class="post" id="new" data-tags="go"><div class="date"><time datetime="2025-04-02T12:00:00Z"></time></div><p>new</p></div>
+    </div></body></html>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{
+        Title: "yarrie",
+        BaseUrl: "http://yarrie.net/microblog",
+        Sanitize: htmlhelper.NewSanitizePolicy(),
+        Warnings: &FeedWarnings{},
+    }
+    stderr := captureStderr(t, func() {
+        if _, err := GenRss(doc, metadata); err != nil {
+            t.Errorf("failed to generate rss: %s", err)
+        }
+        if _, err := GenTagFeeds(doc, metadata, "rss", func(slug string) string { return slug }); err != nil {
+            t.Errorf("failed to generate tag feeds: %s", err)
+        }
+    })
+    for _, warning := range []string{"[warning] stripped from post old", "[warning] post new (2025-04-02 12:00) is newer than post old"} {
+        if n := strings.Count(stderr, warning); n != 1 {
+            t.Errorf("expected '%s' to be printed once not %d times:\n%s", warning, n, stderr)
+        }
+    }
+}
+
+// Testing that tags which differ other than in case but have the same slug
+// are reported rather than sharing a feed.
+func TestGenTagFeedsSlugCollision(t *testing.T) {
+    doc, err := html.Parse(strings.NewReader(`<html><body><div id="posts">
+        <div class="post" id="second" data-tags="C++"><div class="date"><time datetime="2025-04-02T12:00:00Z"></time></div><p>second</p></div>
+        <div class="post" id="first" data-tags="c"><div class="date"><time datetime="2025-04-01T12:00:00Z"></time></div><p>first</p></div>
+    </div></body></html>`))
+    if err != nil {
+        t.Fatalf("failed to parse microblog: %s", err)
+    }
+    metadata := &RSSMetadata{Title: "yarrie", BaseUrl: "http://yarrie.net/microblog"}
+    _, err = GenTagFeeds(doc, metadata, "rss", func(slug string) string { return slug })
+    if err == nil || !strings.Contains(err.Error(), "'C++' and 'c'") {
+        t.Errorf("expected an error naming both tags, got %v", err)
+    }
+}
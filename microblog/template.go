@@ -0,0 +1,162 @@
+package microblog
+
+import (
+    "golang.org/x/net/html"
+    h "html"
+    "fmt"
+    "os"
+    "slices"
+    "strings"
+    "text/template"
+    "time"
+)
+
+// Kind of post rendered when none is chosen.
+const DefaultKind = "note"
+
+// Built-in post templates, one named template per kind. Spacing is important
+// and dependant on correct indentation on insertion, each post is inserted
+// at the end of the line of <div id="posts"> and followed by the newline and
+// indentation of the previous first post.
+const defaultTemplates = `{{define "note"}}
+        <div class="post" id="{{.ID}}"{{if .Title}} data-title="{{escape .Title}}"{{end}}{{if .Tags}} data-tags="{{escape (join .Tags ",")}}"{{end}}>
+            <div class="date">
+                <a href="#{{.ID}}" class="post-link"><time datetime="{{.Datetime}}"><p>{{.DisplayDate}}</p></time></a>
+            </div>
+            {{indent 12 .Body}}
+        </div>{{end}}{{define "photo"}}
+        <div class="post photo" id="{{.ID}}"{{if .Title}} data-title="{{escape .Title}}"{{end}}{{if .Tags}} data-tags="{{escape (join .Tags ",")}}"{{end}}>
+            <div class="date">
+                <a href="#{{.ID}}" class="post-link"><time datetime="{{.Datetime}}"><p>{{.DisplayDate}}</p></time></a>
+            </div>
+            <figure>
+                {{indent 16 .Body}}{{if .Title}}
+                <figcaption>{{escape .Title}}</figcaption>{{end}}
+            </figure>
+        </div>{{end}}{{define "link"}}
+        <div class="post link" id="{{.ID}}"{{if .Title}} data-title="{{escape .Title}}"{{end}}{{if .Tags}} data-tags="{{escape (join .Tags ",")}}"{{end}}>
+            <div class="date">
+                <a href="#{{.ID}}" class="post-link"><time datetime="{{.Datetime}}"><p>{{.DisplayDate}}</p></time></a>
+            </div>{{if .Title}}
+            <h2>{{escape .Title}}</h2>{{end}}
+            {{indent 12 .Body}}
+        </div>{{end}}{{define "quote"}}
+        <div class="post quote" id="{{.ID}}"{{if .Title}} data-title="{{escape .Title}}"{{end}}{{if .Tags}} data-tags="{{escape (join .Tags ",")}}"{{end}}>
+            <div class="date">
+                <a href="#{{.ID}}" class="post-link"><time datetime="{{.Datetime}}"><p>{{.DisplayDate}}</p></time></a>
+            </div>
+            <blockquote>
+                {{indent 16 .Body}}
+            </blockquote>{{if .Title}}
+            <p class="cite">— {{escape .Title}}</p>{{end}}
+        </div>{{end}}`
+
+// Fields available to post templates.
+type TemplateData struct {
+    // ID of the post.
+    ID string
+    // RFC3339 datetime of the post, used by <time datetime>.
+    Datetime string
+    // Human readable date of the post.
+    DisplayDate string
+    // HTML content of the post.
+    Body string
+    // Optional title of the post.
+    Title string
+    // Optional tags of the post.
+    Tags []string
+}
+
+// Functions available to post templates.
+var templateFuncs = template.FuncMap{
+    // escape text for use in HTML content or attributes
+    "escape": h.EscapeString,
+    // join strings with a separator
+    "join": func(s []string, sep string) string {
+        return strings.Join(s, sep)
+    },
+    // indent every line after the first by n spaces, the first line follows
+    // the template's own indentation
+    "indent": func(n int, s string) string {
+        return strings.ReplaceAll(s, "\n", "\n" + strings.Repeat(" ", n))
+    },
+}
+
+// The built-in post templates with the kinds note, photo, link and quote.
+func DefaultTemplates() *template.Template {
+    return template.Must(template.New(DefaultKind).Funcs(templateFuncs).Parse(defaultTemplates))
+}
+
+// Load user defined post templates from a text/template file on top of the
+// built-in templates. Each kind is a named template, e.g.
+// {{define "photo"}}...{{end}}, which replaces the built-in template of the
+// same name. Content outside of any definition replaces the default kind.
+// See TemplateData for the available fields, and the functions escape, join
+// and indent.
+func LoadTemplates(path string) (*template.Template, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    tmpl, err := DefaultTemplates().Clone()
+    if err != nil {
+        return nil, err
+    }
+    // parsing as the default kind means empty top level content keeps the
+    // built-in default kind
+    return tmpl.New(DefaultKind).Parse(string(data))
+}
+
+// The kinds defined by the templates, sorted.
+func TemplateKinds(tmpl *template.Template) []string {
+    var kinds []string
+    for _, t := range tmpl.Templates() {
+        if t.Tree != nil {
+            kinds = append(kinds, t.Name())
+        }
+    }
+    slices.Sort(kinds)
+    return kinds
+}
+
+// Render a post of the kind with the templates. The rendered post is checked
+// against the schema expected by parseMicroblog so that a template cannot
+// produce posts missing from the feed. Returns the rendered post, an error
+// when the kind is unknown or the schema does not match.
+func renderPost(tmpl *template.Template, kind string, data TemplateData, datetime time.Time) (string, error) {
+    t := tmpl.Lookup(kind)
+    if t == nil || t.Tree == nil {
+        return "", fmt.Errorf("unknown post kind '%s' (expected one of %s)", kind, strings.Join(TemplateKinds(tmpl), ", "))
+    }
+    var sb strings.Builder
+    if err := t.Execute(&sb, data); err != nil {
+        return "", err
+    }
+    rendered := sb.String()
+    if err := checkSchema(rendered, data.ID, datetime); err != nil {
+        return "", fmt.Errorf("post kind '%s' does not match the post schema: %s", kind, err)
+    }
+    return rendered, nil
+}
+
+// Check that a rendered post is parsed by parseMicroblog as a single post with
+// the ID and datetime.
+func checkSchema(rendered string, id string, datetime time.Time) error {
+    doc, err := html.Parse(strings.NewReader(`<div id="posts">` + rendered + `</div>`))
+    if err != nil {
+        return err
+    }
+    posts := parseMicroblog(doc)
+    if len(posts) != 1 {
+        return fmt.Errorf("expected one .post with a date, found %d", len(posts))
+    }
+    post := posts[0]
+    if post.ID != id {
+        return fmt.Errorf("expected post id '%s' not '%s'", id, post.ID)
+    }
+    // datetime attribute is only second resolution
+    if !post.DatePosted.Equal(datetime.Truncate(time.Second)) {
+        return fmt.Errorf("expected <time datetime> of the post in div.date to be %s", datetime.Format(time.RFC3339))
+    }
+    return nil
+}
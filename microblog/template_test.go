@@ -0,0 +1,122 @@
+package microblog
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Write a template file for the test. Returns its path.
+func writeTemplates(t *testing.T, src string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "templates.html")
+    if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+        t.Fatalf("failed to write templates: %s", err)
+    }
+    return path
+}
+
+// Data of a post rendered at the datetime.
+func templateData(datetime time.Time) TemplateData {
+    return TemplateData{
+        ID: "my-post",
+        Datetime: datetime.Format(time.RFC3339),
+        DisplayDate: "april 14, 2025",
+        Body: "<p>body &amp; more</p>",
+        Title: "A \"title\"",
+        Tags: []string{"music", "news"},
+    }
+}
+
+// Testing that every built-in kind renders a post matching the schema.
+func TestDefaultTemplates(t *testing.T) {
+    tmpl := DefaultTemplates()
+    if kinds := strings.Join(TemplateKinds(tmpl), ","); kinds != "link,note,photo,quote" {
+        t.Errorf("unexpected built-in kinds '%s'", kinds)
+    }
+    datetime := time.Date(2025, time.April, 14, 12, 26, 44, 0, time.UTC)
+    for _, kind := range TemplateKinds(tmpl) {
+        rendered, err := renderPost(tmpl, kind, templateData(datetime), datetime)
+        if err != nil {
+            t.Errorf("failed to render %s: %s", kind, err)
+            continue
+        }
+        if !strings.Contains(rendered, `data-title="A &#34;title&#34;"`) || !strings.Contains(rendered, `data-tags="music,news"`) {
+            t.Errorf("expected the %s post to have the escaped title and tags:\n%s", kind, rendered)
+        }
+    }
+}
+
+// Testing that LoadTemplates replaces and adds kinds on top of the built-in
+// templates, and that renderPost rejects unknown kinds and posts which do not
+// match the schema.
+func TestLoadTemplates(t *testing.T) {
+    path := writeTemplates(t, `{{define "photo"}}
+        <div class="post photo" id="{{.ID}}"><div class="date"><time datetime="{{.Datetime}}">{{.DisplayDate}}</time></div>{{.Body}}</div>{{end}}{{define "status"}}
+        <div class="post status" id="{{.ID}}"><div class="date"><time datetime="{{.Datetime}}"></time></div><p>{{escape .Title}}</p></div>{{end}}{{define "broken"}}
+        <div class="post" id="{{.ID}}"><p>missing its date</p></div>{{end}}{{define "wrong-id"}}
+        <div class="post" id="other"><div class="date"><time datetime="{{.Datetime}}"></time></div></div>{{end}}`)
+    tmpl, err := LoadTemplates(path)
+    if err != nil {
+        t.Fatalf("failed to load templates: %s", err)
+    }
+    if kinds := strings.Join(TemplateKinds(tmpl), ","); kinds != "broken,link,note,photo,quote,status,wrong-id" {
+        t.Errorf("unexpected kinds '%s'", kinds)
+    }
+
+    datetime := time.Date(2025, time.April, 14, 12, 26, 44, 0, time.UTC)
+    data := templateData(datetime)
+    rendered, err := renderPost(tmpl, "photo", data, datetime)
+    if err != nil {
+        t.Fatalf("failed to render the replaced photo kind: %s", err)
+    }
+    if strings.Contains(rendered, "<figure>") {
+        t.Errorf("expected the photo kind to be replaced:\n%s", rendered)
+    }
+    if rendered, err = renderPost(tmpl, "status", data, datetime); err != nil || !strings.Contains(rendered, "<p>A &#34;title&#34;</p>") {
+        t.Errorf("expected the added status kind to render (%v):\n%s", err, rendered)
+    }
+    // built-in kinds which are not replaced are kept
+    if _, err = renderPost(tmpl, "note", data, datetime); err != nil {
+        t.Errorf("failed to render the built-in note kind: %s", err)
+    }
+
+    failures := map[string]string{
+        "broken": "expected one .post with a date",
+        "wrong-id": "expected post id 'my-post'",
+        "video": "unknown post kind 'video' (expected one of broken, link, note",
+    }
+    for kind, expected := range failures {
+        _, err := renderPost(tmpl, kind, data, datetime)
+        if err == nil || !strings.Contains(err.Error(), expected) {
+            t.Errorf("expected rendering %s to fail with '%s', got %v", kind, expected, err)
+        }
+    }
+    // the datetime must be the one of the post
+    if _, err := renderPost(tmpl, "note", data, datetime.Add(time.Hour)); err == nil || !strings.Contains(err.Error(), "<time datetime>") {
+        t.Errorf("expected a mismatched datetime to fail, got %v", err)
+    }
+
+    if _, err := LoadTemplates(writeTemplates(t, `{{define "note"}}{{.ID}`)); err == nil {
+        t.Errorf("expected an invalid template to fail to load")
+    }
+    if _, err := LoadTemplates(filepath.Join(t.TempDir(), "missing.html")); err == nil {
+        t.Errorf("expected a missing template file to fail to load")
+    }
+}
+
+// Testing that content outside of any definition replaces the default kind.
+func TestLoadTemplatesDefaultKind(t *testing.T) {
+    tmpl, err := LoadTemplates(writeTemplates(t, `
+        <div class="post custom" id="{{.ID}}"><div class="date"><time datetime="{{.Datetime}}"></time></div>{{.Body}}</div>`))
+    if err != nil {
+        t.Fatalf("failed to load templates: %s", err)
+    }
+    datetime := time.Date(2025, time.April, 14, 12, 26, 44, 0, time.UTC)
+    rendered, err := renderPost(tmpl, DefaultKind, templateData(datetime), datetime)
+    if err != nil || !strings.Contains(rendered, `class="post custom"`) {
+        t.Errorf("expected the default kind to be replaced (%v):\n%s", err, rendered)
+    }
+}
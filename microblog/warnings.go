@@ -0,0 +1,29 @@
+package microblog
+
+import (
+    "fmt"
+    "os"
+)
+
+// Warnings of one run generating feeds. A run generates several feeds of the
+// same posts, e.g. archives and tag feeds, and each warning is only printed
+// the first time. Copies of the metadata share the warnings.
+type FeedWarnings struct {
+    printed map[string]bool
+}
+
+// Print a warning to stderr unless it has been printed already. A nil
+// FeedWarnings prints every warning.
+func (w *FeedWarnings) warnf(format string, args ...any) {
+    message := fmt.Sprintf(format, args...)
+    if w != nil {
+        if w.printed[message] {
+            return
+        }
+        if w.printed == nil {
+            w.printed = map[string]bool{}
+        }
+        w.printed[message] = true
+    }
+    fmt.Fprintf(os.Stderr, "[warning] %s\n", message)
+}
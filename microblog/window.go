@@ -0,0 +1,74 @@
+package microblog
+
+import (
+    "golang.org/x/net/html"
+    "slices"
+)
+
+// Warn about each post dated after the post above it. New posts are inserted
+// at the top of the document, so document order and date order disagreeing
+// usually means a post is mis-dated.
+func warnDateOrder(posts []Post, warnings *FeedWarnings) {
+    var previous *Post
+    for i := range posts {
+        post := &posts[i]
+        if post.Deleted {
+            continue
+        }
+        if previous != nil && post.DatePosted.After(previous.DatePosted) {
+            warnings.warnf("post %s (%s) is newer than post %s (%s) above it, it may be mis-dated",
+                post.ID, post.DatePosted.Format("2006-01-02 15:04"), previous.ID, previous.DatePosted.Format("2006-01-02 15:04"))
+        }
+        previous = post
+    }
+}
+
+// Select the posts of a feed using the window filter. Posts are sorted newest
+// first by DatePosted regardless of document order before the filter is
+// applied, so a limit keeps the newest posts. Deleted posts are not counted
+// by the limit, they are kept when deleted within the since and until dates
+// (or when the deletion date is unknown) and follow the posts.
+func windowPosts(posts []Post, window Filter) []Post {
+
+    var live []Post
+    var deleted []Post
+    for _, post := range posts {
+        if !post.Deleted {
+            live = append(live, post)
+            continue
+        }
+        if !post.DateDeleted.IsZero() {
+            if !window.Since.IsZero() && post.DateDeleted.Before(window.Since) {
+                continue
+            }
+            if !window.Until.IsZero() && post.DateDeleted.After(window.Until) {
+                continue
+            }
+        }
+        deleted = append(deleted, post)
+    }
+
+    slices.SortStableFunc(live, func(a, b Post) int {
+        return b.DatePosted.Compare(a.DatePosted)
+    })
+    return append(FilterPosts(live, window), deleted...)
+}
+
+// Every post of the microblog document for feeds, with a warning for each
+// post which seems mis-dated. The posts are matched to the previous feed
+// when merging.
+func parseFeedPosts(doc *html.Node, metadata *RSSMetadata) []Post {
+    posts := parseMicroblogWarn(doc, metadata.Warnings)
+    warnDateOrder(posts, metadata.Warnings)
+    if metadata.Merge != nil {
+        metadata.Merge.prepare(posts, metadata)
+    }
+    return posts
+}
+
+// Posts of a feed generated from the microblog document, see windowPosts.
+// Every post of the document is matched to the previous feed first when
+// merging.
+func feedPosts(doc *html.Node, metadata *RSSMetadata) []Post {
+    return windowPosts(parseFeedPosts(doc, metadata), metadata.Window)
+}
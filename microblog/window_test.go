@@ -0,0 +1,39 @@
+package microblog
+
+import (
+    "testing"
+)
+
+// Testing that windowPosts sorts posts by date regardless of document order
+// before applying the limit and dates, keeping deleted posts within the dates.
+func TestWindowPosts(t *testing.T) {
+    posts := []Post{
+        {ID: "d", DatePosted: aprilUTC(4, 12)},
+        {ID: "b", DatePosted: aprilUTC(2, 12)},
+        // mis-dated, newer than the posts above it
+        {ID: "e", DatePosted: aprilUTC(5, 12)},
+        {ID: "gone", Deleted: true, DateDeleted: aprilUTC(3, 12)},
+        {ID: "old-gone", Deleted: true, DateDeleted: aprilUTC(1, 12)},
+        {ID: "a", DatePosted: aprilUTC(1, 12)},
+        {ID: "c", DatePosted: aprilUTC(3, 12)},
+    }
+
+    cases := []struct {
+        window Filter
+        expected string
+    }{
+        {Filter{}, "e,d,c,b,a,gone,old-gone"},
+        {Filter{Limit: 2}, "e,d,gone,old-gone"},
+        {Filter{Since: aprilUTC(2, 12)}, "e,d,c,b,gone"},
+        {Filter{Since: aprilUTC(2, 12), Until: aprilUTC(3, 12), Limit: 1}, "c,gone"},
+    }
+    for _, c := range cases {
+        if got := postIDs(windowPosts(posts, c.window)); got != c.expected {
+            t.Errorf("window %+v expected '%s' not '%s'", c.window, c.expected, got)
+        }
+    }
+    // the document order is left unchanged
+    if got := postIDs(posts); got != "d,b,e,gone,old-gone,a,c" {
+        t.Errorf("expected posts to be left in document order not '%s'", got)
+    }
+}
@@ -0,0 +1,190 @@
+package rsshelper
+
+import (
+    "encoding/xml"
+    "strings"
+    "time"
+)
+
+// Namespace of Atom 1.0 (RFC 4287).
+const AtomNamespace = "http://www.w3.org/2005/Atom"
+
+// Namespace of Atom tombstones (RFC 6721).
+const TombstonesNamespace = "http://purl.org/atompub/tombstones/1.0"
+
+// Atom 1.0 feed, parallel to RSS. Times are encoded as RFC3339.
+type AtomFeed struct {
+    XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+    // Permanent unique IRI of the feed.
+    ID string `xml:"id"`
+    Title string `xml:"title"`
+    Subtitle string `xml:"subtitle,omitempty"`
+    // Most recent time the feed changed.
+    Updated time.Time `xml:"updated"`
+    Author *AtomPerson `xml:"author,omitempty"`
+    Links []AtomLink `xml:"link"`
+    // Whether the feed is an archive document, see HistoryArchive.
+    Archive *HistoryArchive `xml:"http://purl.org/syndication/history/1.0 archive,omitempty"`
+    Entries []AtomEntry `xml:"entry"`
+    // Entries which have been deleted, see RFC 6721.
+    DeletedEntries []AtomDeletedEntry `xml:"http://purl.org/atompub/tombstones/1.0 deleted-entry"`
+}
+
+// Entry of an Atom feed.
+type AtomEntry struct {
+    // Permanent unique IRI of the entry.
+    ID string `xml:"id"`
+    Title string `xml:"title"`
+    Updated time.Time `xml:"updated"`
+    // Time of the first publication of the entry, optional.
+    Published *time.Time `xml:"published,omitempty"`
+    Author *AtomPerson `xml:"author,omitempty"`
+    Links []AtomLink `xml:"link"`
+    Categories []AtomCategory `xml:"category"`
+    // Short summary of the entry, uses the content type.
+    Summary *AtomContent `xml:"summary,omitempty"`
+    Content *AtomContent `xml:"content,omitempty"`
+}
+
+// Person construct of an Atom feed or entry, e.g. the author.
+type AtomPerson struct {
+    Name string `xml:"name"`
+    URI string `xml:"uri,omitempty"`
+    Email string `xml:"email,omitempty"`
+}
+
+// Link of an Atom feed or entry. The relation is "alternate" when empty.
+type AtomLink struct {
+    Href string `xml:"href,attr"`
+    Rel string `xml:"rel,attr,omitempty"`
+    Type string `xml:"type,attr,omitempty"`
+}
+
+// Category of an Atom entry, optionally within a scheme.
+type AtomCategory struct {
+    Term string `xml:"term,attr"`
+    Scheme string `xml:"scheme,attr,omitempty"`
+    // Human readable label, the term when empty.
+    Label string `xml:"label,attr,omitempty"`
+}
+
+// Content of an Atom entry. The type is "text", "html" or "xhtml", HTML
+// content is escaped once as character data.
+type AtomContent struct {
+    Type string `xml:"type,attr,omitempty"`
+    Body string `xml:",chardata"`
+}
+
+// Tombstone of a deleted Atom entry, referencing the ID of the entry.
+type AtomDeletedEntry struct {
+    Ref string `xml:"ref,attr"`
+    When time.Time `xml:"when,attr"`
+}
+
+// Encode the Atom feed as indented XML with an XML declaration.
+func EncodeAtom(feed *AtomFeed) ([]byte, error) {
+    data, err := xml.MarshalIndent(feed, "", "    ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(xml.Header), data...), nil
+}
+
+func (f *AtomFeed) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    type Alias AtomFeed
+    aux := &struct{
+        Updated string `xml:"updated"`
+        *Alias
+    }{
+        Alias: (*Alias)(f),
+    }
+    if err := d.DecodeElement(aux, &start); err != nil {
+        return err
+    }
+    // an invalid date is left as zero rather than failing the whole feed
+    f.Updated, _ = ParseDate(aux.Updated)
+    return nil
+}
+
+func (e *AtomEntry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    type Alias AtomEntry
+    aux := &struct{
+        Updated string `xml:"updated"`
+        Published string `xml:"published"`
+        *Alias
+    }{
+        Alias: (*Alias)(e),
+    }
+    if err := d.DecodeElement(aux, &start); err != nil {
+        return err
+    }
+    // invalid dates are left as zero, or out when optional
+    e.Updated, _ = ParseDate(aux.Updated)
+    e.Published = parseOptionalDate(aux.Published)
+    return nil
+}
+
+func (c *AtomContent) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    aux := &struct{
+        Type string `xml:"type,attr"`
+        Body string `xml:",chardata"`
+        Inner string `xml:",innerxml"`
+    }{}
+    if err := d.DecodeElement(aux, &start); err != nil {
+        return err
+    }
+    c.Type = aux.Type
+    c.Body = aux.Body
+    if aux.Type == "xhtml" {
+        c.Body = unwrapXHTMLDiv(aux.Inner)
+    }
+    return nil
+}
+
+func (e *AtomDeletedEntry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    type Alias AtomDeletedEntry
+    aux := &struct{
+        When string `xml:"when,attr"`
+        *Alias
+    }{
+        Alias: (*Alias)(e),
+    }
+    if err := d.DecodeElement(aux, &start); err != nil {
+        return err
+    }
+    e.When, _ = ParseDate(aux.When)
+    return nil
+}
+
+// Markup of xhtml content without the div which wraps it, see RFC 4287
+// section 3.1.1.3. Content not wrapped in a div is returned as it is.
+func unwrapXHTMLDiv(inner string) string {
+    inner = strings.TrimSpace(inner)
+    end := strings.Index(inner, ">")
+    if !strings.HasPrefix(inner, "<") || end == -1 {
+        return inner
+    }
+    fields := strings.Fields(strings.TrimSuffix(inner[1:end], "/"))
+    if len(fields) == 0 || (fields[0] != "div" && !strings.HasSuffix(fields[0], ":div")) {
+        return inner
+    }
+    if inner[end-1] == '/' {
+        return ""
+    }
+    closing := strings.LastIndex(inner, "</")
+    if closing < end {
+        return inner
+    }
+    return strings.TrimSpace(inner[end+1:closing])
+}
+
+// Decode an Atom feed leniently, as RSS is decoded: with HTML entities, the
+// charsets of Decode and invalid dates left as zero.
+func DecodeAtom(data []byte) (*AtomFeed, error) {
+    var feed AtomFeed
+    err := unmarshalFeed(data, &feed)
+    if err != nil {
+        return nil, err
+    }
+    return &feed, nil
+}
@@ -0,0 +1,185 @@
+package rsshelper
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+)
+
+var exampleAtomFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:at="http://purl.org/atompub/tombstones/1.0">
+    <id>http://yarrie.net/microblog</id>
+    <title>yarrie</title>
+    <updated>2025-04-14T12:26:44+01:00</updated>
+    <author><name>yarrie</name></author>
+    <link href="http://yarrie.net/microblog/atom.xml" rel="self" type="application/atom+xml"/>
+    <link href="http://yarrie.net/microblog"/>
+    <entry>
+        <id>http://yarrie.net/microblog#exampleid</id>
+        <title>hello</title>
+        <updated>2025-04-14T12:26:44+01:00</updated>
+        <link href="http://yarrie.net/microblog#exampleid" rel="alternate"/>
+        <content type="html">&lt;p&gt;hello &amp;amp; goodbye&lt;/p&gt;</content>
+    </entry>
+    <at:deleted-entry ref="http://yarrie.net/microblog#gone" when="2025-04-13T08:00:00Z"/>
+</feed>`
+
+// Testing decoding of an Atom feed with an entry and a tombstone.
+func TestDecodeAtom(t *testing.T) {
+    feed, err := DecodeAtom([]byte(exampleAtomFeed))
+    if err != nil {
+        t.Fatalf("failed to decode atom feed: %s", err)
+    }
+    if feed.ID != "http://yarrie.net/microblog" || feed.Title != "yarrie" {
+        t.Errorf("unexpected feed id '%s' or title '%s'", feed.ID, feed.Title)
+    }
+    if feed.Author == nil || feed.Author.Name != "yarrie" {
+        t.Errorf("expected feed author 'yarrie', got %v", feed.Author)
+    }
+    if len(feed.Links) != 2 || feed.Links[0].Rel != "self" || feed.Links[1].Href != "http://yarrie.net/microblog" {
+        t.Errorf("unexpected feed links %v", feed.Links)
+    }
+    if len(feed.Entries) != 1 {
+        t.Fatalf("expected 1 entry, got %d", len(feed.Entries))
+    }
+    entry := feed.Entries[0]
+    expectedUpdated := aprilUTC(14, 11, 26, 44)
+    if !entry.Updated.Equal(expectedUpdated) {
+        t.Errorf("expected entry updated %s not %s", expectedUpdated, entry.Updated)
+    }
+    if entry.Content == nil || entry.Content.Type != "html" || entry.Content.Body != "<p>hello &amp; goodbye</p>" {
+        t.Errorf("unexpected entry content %v", entry.Content)
+    }
+    if len(feed.DeletedEntries) != 1 || feed.DeletedEntries[0].Ref != "http://yarrie.net/microblog#gone" {
+        t.Errorf("unexpected deleted entries %v", feed.DeletedEntries)
+    }
+}
+
+// Testing that Atom is decoded as leniently as RSS: in Latin-1, with HTML
+// entities, invalid dates left as zero and xhtml content unwrapped from its
+// div.
+func TestDecodeAtomLenient(t *testing.T) {
+    data := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>" + `
+<feed xmlns="http://www.w3.org/2005/Atom">
+    <id>http://yarrie.net/microblog</id>
+    <title>caf` + "\xe9" + `&nbsp;yarrie</title>
+    <updated>yesterday</updated>
+    <entry>
+        <id>http://yarrie.net/microblog#second</id>
+        <title>second</title>
+        <updated>Mon, 14 Apr 2025 12:26:44 +0100</updated>
+        <published>not a date</published>
+        <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>second &amp; <b>newest</b></p></div></content>
+    </entry>
+</feed>`
+    feed, err := DecodeAtom([]byte(data))
+    if err != nil {
+        t.Fatalf("failed to decode atom feed: %s", err)
+    }
+    if feed.Title != "caf\u00e9\u00a0yarrie" {
+        t.Errorf("unexpected title %q", feed.Title)
+    }
+    if !feed.Updated.IsZero() {
+        t.Errorf("expected the invalid feed updated to be zero not %s", feed.Updated)
+    }
+    if len(feed.Entries) != 1 {
+        t.Fatalf("expected 1 entry, got %d", len(feed.Entries))
+    }
+    entry := feed.Entries[0]
+    if !entry.Updated.Equal(aprilUTC(14, 11, 26, 44)) || entry.Published != nil {
+        t.Errorf("expected the RFC 822 update to be read and the invalid published left out, got %s and %v", entry.Updated, entry.Published)
+    }
+    if entry.Content == nil || entry.Content.Body != "<p>second &amp; <b>newest</b></p>" {
+        t.Errorf("unexpected xhtml content %v", entry.Content)
+    }
+}
+
+// Testing the xhtml div is removed whatever its prefix, keeping content
+// which is not wrapped.
+func TestUnwrapXHTMLDiv(t *testing.T) {
+    tests := map[string]string{
+        `<div xmlns="http://www.w3.org/1999/xhtml"><p>a</p></div>`: "<p>a</p>",
+        ` <xhtml:div> <p>a</p> <p>b</p> </xhtml:div> `: "<p>a</p> <p>b</p>",
+        `<div/>`: "",
+        `<p>a</p>`: "<p>a</p>",
+        `text`: "text",
+    }
+    for inner, expected := range tests {
+        if got := unwrapXHTMLDiv(inner); got != expected {
+            t.Errorf("expected '%s' unwrapped to '%s' not '%s'", inner, expected, got)
+        }
+    }
+}
+
+// Testing that an encoded Atom feed decodes to the same feed.
+func TestAtomRoundTrip(t *testing.T) {
+    updated := time.Date(2025, time.April, 14, 12, 26, 44, 0, time.FixedZone("", 3600))
+    feed := &AtomFeed{
+        ID: "http://yarrie.net/microblog",
+        Title: "yarrie",
+        Subtitle: "yarrie's microblog",
+        Updated: updated,
+        Author: &AtomPerson{Name: "yarrie"},
+        Links: []AtomLink{
+            {Href: "http://yarrie.net/microblog/atom.xml", Rel: "self", Type: "application/atom+xml"},
+            {Href: "http://yarrie.net/microblog", Rel: "alternate", Type: "text/html"},
+        },
+        Entries: []AtomEntry{
+            {
+                ID: "http://yarrie.net/microblog#exampleid",
+                Title: "hello",
+                Updated: updated,
+                Published: &updated,
+                Links: []AtomLink{{Href: "http://yarrie.net/microblog#exampleid", Rel: "alternate"}},
+                Content: &AtomContent{Type: "html", Body: `<p>a <a href="x?a=1&b=2">link</a></p>`},
+            },
+        },
+        DeletedEntries: []AtomDeletedEntry{
+            {Ref: "http://yarrie.net/microblog#gone", When: updated.Add(-time.Hour)},
+        },
+    }
+
+    data, err := EncodeAtom(feed)
+    if err != nil {
+        t.Fatalf("failed to encode atom feed: %s", err)
+    }
+    if !strings.HasPrefix(string(data), "<?xml") {
+        t.Errorf("expected encoded feed to begin with an xml declaration")
+    }
+    if !strings.Contains(string(data), `<content type="html">&lt;p&gt;a &lt;a href=&#34;x?a=1&amp;b=2&#34;&gt;link&lt;/a&gt;&lt;/p&gt;</content>`) {
+        t.Errorf("expected html content to be escaped once:\n%s", data)
+    }
+
+    decoded, err := DecodeAtom(data)
+    if err != nil {
+        t.Fatalf("failed to decode encoded atom feed: %s", err)
+    }
+    // names are only set when decoding
+    decoded.XMLName = feed.XMLName
+    if !reflect.DeepEqual(normaliseAtomTimes(decoded), normaliseAtomTimes(feed)) {
+        t.Errorf("round trip changed the feed\nexpected: %+v\ngot:      %+v", feed, decoded)
+    }
+}
+
+// Convert every time of the feed to UTC so that feeds can be compared with
+// reflect.DeepEqual regardless of the location of each time.
+func normaliseAtomTimes(feed *AtomFeed) *AtomFeed {
+    f := *feed
+    f.Updated = f.Updated.UTC()
+    f.Entries = nil
+    for _, e := range feed.Entries {
+        e.Updated = e.Updated.UTC()
+        if e.Published != nil {
+            published := e.Published.UTC()
+            e.Published = &published
+        }
+        f.Entries = append(f.Entries, e)
+    }
+    f.DeletedEntries = nil
+    for _, d := range feed.DeletedEntries {
+        d.When = d.When.UTC()
+        f.DeletedEntries = append(f.DeletedEntries, d)
+    }
+    return &f
+}
@@ -0,0 +1,88 @@
+package rsshelper
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Offsets of the named zones of RFC 822 and other common abbreviations found
+// in feeds. time.Parse only knows the abbreviations of the local zone and
+// otherwise gives them a zero offset.
+var namedZones = map[string]string{
+    "UT": "+0000", "UTC": "+0000", "GMT": "+0000", "Z": "+0000",
+    "EST": "-0500", "EDT": "-0400", "CST": "-0600", "CDT": "-0500",
+    "MST": "-0700", "MDT": "-0600", "PST": "-0800", "PDT": "-0700",
+    "AKST": "-0900", "AKDT": "-0800", "HST": "-1000",
+    "BST": "+0100", "WET": "+0000", "WEST": "+0100", "CET": "+0100",
+    "CEST": "+0200", "EET": "+0200", "EEST": "+0300", "MSK": "+0300",
+    "JST": "+0900", "KST": "+0900", "AEST": "+1000", "AEDT": "+1100",
+    "NZST": "+1200", "NZDT": "+1300",
+}
+
+// Layouts of RFC 822 and RFC 1123 dates once the weekday is removed and a
+// named zone is replaced by its offset, with or without seconds, two or four
+// digit years, short or long month names and an optional zone.
+var rfc822Layouts = func() []string {
+    var layouts []string
+    for _, month := range []string{"Jan", "January"} {
+        for _, year := range []string{"2006", "06"} {
+            for _, clock := range []string{"15:04:05", "15:04"} {
+                for _, zone := range []string{" -0700", " -07:00", ""} {
+                    layouts = append(layouts, "2 " + month + " " + year + " " + clock + zone)
+                }
+            }
+        }
+    }
+    return layouts
+}()
+
+// Layouts of RFC3339 and ISO 8601 dates, e.g. dc:date. Dates without a zone
+// are in UTC.
+var isoLayouts = []string{
+    time.RFC3339,
+    "2006-01-02T15:04Z07:00",
+    "2006-01-02 15:04:05Z07:00",
+    "2006-01-02T15:04:05",
+    "2006-01-02T15:04",
+    "2006-01-02",
+}
+
+// Parse a date of a feed in the forms of RFC 822 and RFC 1123 (e.g. "Mon, 14
+// Apr 2025 12:26:44 +0100", with or without seconds or the weekday, with a
+// numeric or named zone such as "GMT" or "EST") or RFC3339 (e.g.
+// "2025-04-14T12:26:44+01:00"). Returns an error when no form matches.
+func ParseDate(s string) (time.Time, error) {
+    normalized := strings.Join(strings.Fields(s), " ")
+    for _, layout := range isoLayouts {
+        if t, err := time.Parse(layout, normalized); err == nil {
+            return t, nil
+        }
+    }
+
+    // the weekday is redundant and often misspelled, e.g. "Tues,"
+    if comma := strings.Index(normalized, ","); comma != -1 {
+        normalized = strings.TrimSpace(normalized[comma+1:])
+    }
+    // replace a named zone by its offset
+    if space := strings.LastIndex(normalized, " "); space != -1 {
+        if offset, ok := namedZones[strings.ToUpper(normalized[space+1:])]; ok {
+            normalized = normalized[:space+1] + offset
+        }
+    }
+    for _, layout := range rfc822Layouts {
+        if t, err := time.Parse(layout, normalized); err == nil {
+            return t, nil
+        }
+    }
+    return time.Time{}, fmt.Errorf("unrecognised date '%s' (expected RFC 822 or RFC3339)", s)
+}
+
+// Parse an optional date with ParseDate, nil when it is empty or invalid.
+func parseOptionalDate(s string) *time.Time {
+    t, err := ParseDate(s)
+    if err != nil {
+        return nil
+    }
+    return &t
+}
@@ -1,15 +1,14 @@
 package rsshelper
 
 import (
-    "fmt"
     "encoding/xml"
-    "time"
 )
 
 func (i *Item) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
     type Alias Item
     aux := &struct{
         PubDate string `xml:"pubDate"`
+        Updated string `xml:"http://www.w3.org/2005/Atom updated"`
         *Alias
     }{
         Alias: (*Alias)(i),
@@ -18,22 +17,31 @@ func (i *Item) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
     if err != nil {
         return err
     }
-
-    t, err := time.Parse("Mon, 02 Jan 2006 15:04:05 ", aux.PubDate)
-    if err != nil {
-        return fmt.Errorf("error parsing pubDate: %v", err)
+    // the update is optional, an invalid date is left out
+    if updated, err := ParseDate(aux.Updated); err == nil {
+        i.Updated = &updated
     }
-
-    i.PubDate = t
+    // an invalid pubDate is left as zero rather than failing the whole feed
+    i.PubDate, _ = ParseDate(aux.PubDate)
     return nil
 }
 
-func Decode(data []byte) ([]Item, error) {
-    var rss RSS
-    err := xml.Unmarshal(data, &rss)
+func (c *Channel) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    type Alias Channel
+    aux := &struct{
+        PubDate string `xml:"pubDate"`
+        LastBuildDate string `xml:"lastBuildDate"`
+        *Alias
+    }{
+        Alias: (*Alias)(c),
+    }
+    err := d.DecodeElement(aux, &start)
     if err != nil {
-        return nil, err
+        return err
     }
-    return rss.Channel.Items, nil
-}
 
+    // channel dates are optional, invalid dates are left as zero
+    c.PubDate, _ = ParseDate(aux.PubDate)
+    c.LastBuildDate, _ = ParseDate(aux.LastBuildDate)
+    return nil
+}
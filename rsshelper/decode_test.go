@@ -1,6 +1,10 @@
 package rsshelper
 
 import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
     "testing"
 )
 
@@ -20,9 +24,296 @@ var exampleFeed = `
     </channel>
 </rss>`
 
+// Testing valid rss with a date decodes with its zone and the channel.
 func TestDecodeRss(t *testing.T) {
-    // testing valid rss with date
+    feed, err := Decode([]byte(exampleFeed))
+    if err != nil {
+        t.Fatalf("failed to decode rss: %s", err)
+    }
+    if feed.Format != FormatRSS || feed.Version != "2.0" {
+        t.Errorf("expected rss 2.0 not %s %s", feed.Format, feed.Version)
+    }
+    if feed.Title != "yarrie" || feed.Link != "http://yarrie.net/microblog" || feed.Description != "yarrie's microblog" {
+        t.Errorf("unexpected channel %+v", feed)
+    }
+    if len(feed.Items) != 1 {
+        t.Fatalf("expected 1 item not %d", len(feed.Items))
+    }
+    item := feed.Items[0]
+    expected := aprilUTC(14, 11, 26, 44)
+    if !item.Published.Equal(expected) {
+        t.Errorf("expected pubDate %s not %s", expected, item.Published)
+    }
+    if _, offset := item.Published.Zone(); offset != 3600 {
+        t.Errorf("expected the +0100 zone to be kept, got offset %d", offset)
+    }
+    if item.ID != "http://yarrie.net/microblog#exampleid" || item.Summary != "&lt;p&gt;&lt;/p&gt;" || item.Authors[0] != "yarrie" {
+        t.Errorf("unexpected item %+v", item)
+    }
+}
+
+// Testing that an item with an unparseable pubDate decodes with a zero date
+// instead of failing the feed.
+func TestDecodeInvalidPubDate(t *testing.T) {
+    data := strings.Replace(exampleFeed, "Mon, 14 Apr 2025 12:26:44 +0100", "yesterday", 1)
+    feed, err := Decode([]byte(data))
+    if err != nil {
+        t.Fatalf("failed to decode rss: %s", err)
+    }
+    if len(feed.Items) != 1 {
+        t.Fatalf("expected 1 item not %d", len(feed.Items))
+    }
+    if !feed.Items[0].Published.IsZero() {
+        t.Errorf("expected the invalid pubDate to be zero not %s", feed.Items[0].Published)
+    }
+}
+
+// Testing that an RDF item with an invalid dc:date decodes with a zero date.
+func TestDecodeInvalidRDFDate(t *testing.T) {
+    data, err := os.ReadFile(filepath.Join("testdata", "rdf.xml"))
+    if err != nil {
+        t.Fatalf("failed to read rdf.xml: %s", err)
+    }
+    data = []byte(strings.Replace(string(data), "2025-04-14T12:26:44+01:00", "yesterday", 1))
+    feed, err := Decode(data)
+    if err != nil {
+        t.Fatalf("failed to decode rdf: %s", err)
+    }
+    if len(feed.Items) != 1 || !feed.Items[0].Published.IsZero() {
+        t.Errorf("expected one item with a zero date, got %+v", feed.Items)
+    }
+}
 
-    // Decode(data []byte) ([]Item, error)
+// Testing that every dialect in testdata decodes to the expected feed.
+func TestDecodeFixtures(t *testing.T) {
+    second := "http://yarrie.net/microblog#second"
+    tests := map[string]Feed{
+        "rss091.xml": {
+            Format: FormatRSS,
+            Version: "0.91",
+            Title: "yarrie",
+            Link: "http://yarrie.net/microblog",
+            Description: "yarrie's microblog archive",
+            Language: "en-gb",
+            Copyright: "yarrie",
+            ImageURL: "http://yarrie.net/icon.png",
+            Authors: []string{"editor@yarrie.net (yarrie)"},
+            Published: aprilUTC(14, 12, 26, 0),
+            Items: []FeedItem{
+                {ID: second, Title: "café", Link: second, Summary: "second post"},
+                {ID: "http://yarrie.net/microblog#first", Title: "first", Link: "http://yarrie.net/microblog#first"},
+            },
+        },
+        "rss092.xml": {
+            Format: FormatRSS,
+            Version: "0.92",
+            Title: "yarrie",
+            Link: "http://yarrie.net/microblog",
+            Description: "yarrie's microblog",
+            Updated: aprilUTC(15, 13, 0, 0),
+            Items: []FeedItem{{
+                Summary: "<p>untitled</p>",
+                Categories: []string{"music"},
+                Enclosures: []FeedEnclosure{{URL: "http://yarrie.net/audio/song.mp3", Type: "audio/mpeg", Length: 1234}},
+            }},
+        },
+        "rss20.xml": {
+            Format: FormatRSS,
+            Version: "2.0",
+            Title: "yarrie",
+            Link: "http://yarrie.net/microblog",
+            FeedURL: "http://yarrie.net/microblog/rss.xml",
+            Description: "yarrie's microblog",
+            Language: "en-GB",
+            Generator: "yarrienet-tools",
+            Categories: []string{"personal"},
+            Published: aprilUTC(14, 11, 26, 44),
+            Updated: aprilUTC(15, 8, 0, 0),
+            Items: []FeedItem{
+                {
+                    ID: second,
+                    Title: "second & newest",
+                    Link: second,
+                    Summary: "second & newest",
+                    ContentHTML: `<p>second &amp; <a href="https://example.com">newest</a></p>`,
+                    Authors: []string{"yarrie"},
+                    Categories: []string{"music", "news"},
+                    Published: aprilUTC(14, 11, 26, 44),
+                    Updated: aprilUTC(14, 12, 0, 0),
+                },
+                {
+                    ID: "first",
+                    Title: "first",
+                    Link: "http://yarrie.net/microblog#first",
+                    Authors: []string{"yarrie@yarrie.net (yarrie)"},
+                    Published: aprilUTC(11, 0, 38, 0),
+                },
+            },
+        },
+        "rdf.xml": {
+            Format: FormatRDF,
+            Version: "1.0",
+            Title: "yarrie",
+            Link: "http://yarrie.net/microblog",
+            FeedURL: "http://yarrie.net/microblog/index.rdf",
+            Description: "yarrie's microblog",
+            Language: "en-GB",
+            Copyright: "yarrie",
+            ImageURL: "http://yarrie.net/icon.png",
+            Authors: []string{"yarrie"},
+            Updated: aprilUTC(15, 8, 0, 0),
+            Items: []FeedItem{{
+                ID: second,
+                Title: "second",
+                Link: second,
+                Summary: "second post",
+                ContentHTML: "<p>second post</p>",
+                Authors: []string{"yarrie"},
+                Categories: []string{"music"},
+                Published: aprilUTC(14, 11, 26, 44),
+            }},
+        },
+        "atom.xml": {
+            Format: FormatAtom,
+            Version: "1.0",
+            Title: "yarrie",
+            Link: "http://yarrie.net/microblog",
+            FeedURL: "http://yarrie.net/microblog/atom.xml",
+            Description: "yarrie's microblog",
+            Authors: []string{"yarrie"},
+            Updated: aprilUTC(15, 8, 0, 0),
+            Items: []FeedItem{
+                {
+                    ID: second,
+                    Title: "second",
+                    Link: second,
+                    Summary: "second post",
+                    ContentHTML: "<p>second post</p>",
+                    Categories: []string{"music"},
+                    Published: aprilUTC(14, 11, 26, 44),
+                    Updated: aprilUTC(14, 12, 0, 0),
+                    Enclosures: []FeedEnclosure{{URL: "http://yarrie.net/audio/song.mp3", Type: "audio/mpeg"}},
+                },
+                {ID: "http://yarrie.net/microblog#gone", Updated: aprilUTC(15, 8, 0, 0), Deleted: true},
+            },
+        },
+        "jsonfeed10.json": {
+            Format: FormatJSON,
+            Version: "1",
+            Title: "yarrie",
+            Link: "http://yarrie.net/microblog",
+            FeedURL: "http://yarrie.net/microblog/feed.json",
+            Authors: []string{"yarrie"},
+            Items: []FeedItem{{
+                ID: "second",
+                Link: second,
+                ContentText: "second post",
+                Authors: []string{"http://yarrie.net"},
+                Categories: []string{"music"},
+                Published: aprilUTC(14, 11, 26, 44),
+            }},
+        },
+        "jsonfeed11.json": {
+            Format: FormatJSON,
+            Version: "1.1",
+            Title: "yarrie",
+            Link: "http://yarrie.net/microblog",
+            FeedURL: "http://yarrie.net/microblog/feed.json",
+            Language: "en-GB",
+            Authors: []string{"yarrie"},
+            Items: []FeedItem{
+                {
+                    ID: second,
+                    Link: second,
+                    Summary: "second post",
+                    ContentHTML: "<p>second post</p>",
+                    Published: aprilUTC(14, 11, 26, 44),
+                    Updated: aprilUTC(14, 12, 0, 0),
+                    Enclosures: []FeedEnclosure{{URL: "http://yarrie.net/audio/song.mp3", Type: "audio/mpeg", Length: 1234}},
+                },
+                {ID: "http://yarrie.net/microblog#gone", Updated: aprilUTC(15, 8, 0, 0), Deleted: true},
+            },
+        },
+    }
+    for name, expected := range tests {
+        data, err := os.ReadFile(filepath.Join("testdata", name))
+        if err != nil {
+            t.Fatalf("failed to read fixture: %s", err)
+        }
+        feed, err := Decode(data)
+        if err != nil {
+            t.Errorf("failed to decode %s: %s", name, err)
+            continue
+        }
+        feedInUTC(feed)
+        if !reflect.DeepEqual(*feed, expected) {
+            t.Errorf("unexpected feed decoded from %s\nexpected: %+v\ngot:      %+v", name, expected, *feed)
+        }
+    }
 }
 
+// Testing that unknown documents are rejected.
+func TestDecodeUnknown(t *testing.T) {
+    for _, data := range []string{`<html><body></body></html>`, `{"version": "1"}`, ``} {
+        if _, err := Decode([]byte(data)); err == nil {
+            t.Errorf("expected decoding '%s' to fail", data)
+        }
+    }
+}
+
+// Testing that Latin-1 and windows-1252 documents decode to UTF-8, with the
+// punctuation windows-1252 puts in 0x80 to 0x9f.
+func TestDecodeCharsets(t *testing.T) {
+    tests := map[string]string{
+        "ISO-8859-1": "café \u0093",
+        "windows-1252": "café “quoted” — €5",
+    }
+    titles := map[string]string{
+        "ISO-8859-1": "caf\xe9 \x93",
+        "windows-1252": "caf\xe9 \x93quoted\x94 \x97 \x805",
+    }
+    for charset, expected := range tests {
+        data := `<?xml version="1.0" encoding="` + charset + `"?><rss version="2.0"><channel><title>` + titles[charset] + `</title></channel></rss>`
+        feed, err := Decode([]byte(data))
+        if err != nil {
+            t.Fatalf("failed to decode %s rss: %s", charset, err)
+        }
+        if feed.Title != expected {
+            t.Errorf("expected %s title %q not %q", charset, expected, feed.Title)
+        }
+    }
+}
+
+// Testing the forms of dates found in feeds.
+func TestParseDate(t *testing.T) {
+    expected := aprilUTC(14, 11, 26, 44)
+    valid := []string{
+        "Mon, 14 Apr 2025 12:26:44 +0100",
+        "Mon, 14 Apr 2025 11:26:44 GMT",
+        "Mon, 14 Apr 2025 07:26:44 EDT",
+        "14 Apr 2025 11:26:44 UT",
+        "Monday, 14 April 2025 11:26:44 Z",
+        "Mon, 14 Apr 25 12:26:44 +01:00",
+        "  Mon,  14 Apr 2025   12:26:44 +0100 ",
+        "2025-04-14T12:26:44+01:00",
+        "2025-04-14T11:26:44.000Z",
+        "2025-04-14T11:26:44",
+    }
+    for _, s := range valid {
+        got, err := ParseDate(s)
+        if err != nil {
+            t.Errorf("failed to parse '%s': %s", s, err)
+        } else if !got.Equal(expected) {
+            t.Errorf("expected '%s' to be %s not %s", s, expected, got)
+        }
+    }
+    // without seconds and single digit days
+    if got, err := ParseDate("Wed, 2 Apr 2025 09:05 PST"); err != nil || !got.Equal(aprilUTC(2, 17, 5, 0)) {
+        t.Errorf("unexpected date %s (%v)", got, err)
+    }
+    for _, s := range []string{"", "yesterday", "Mon, 14 Foo 2025 12:26:44 +0100", "Mon, 14 Apr 2025 12:26:44 XYZ"} {
+        if _, err := ParseDate(s); err == nil {
+            t.Errorf("expected '%s' to be invalid", s)
+        }
+    }
+}
@@ -0,0 +1,196 @@
+package rsshelper
+
+import (
+    "yarrienet/htmlhelper"
+    "golang.org/x/net/html"
+    "golang.org/x/net/html/atom"
+    "slices"
+    "strings"
+    "time"
+)
+
+// Differences between the items of two feeds, see DiffFeeds.
+type FeedDiff struct {
+    // Items added, removed or changed, in the order of the new feed followed
+    // by removed items in the order of the old feed.
+    Items []ItemDiff `json:"items"`
+}
+
+// Difference of one item between two feeds, matched by ID.
+type ItemDiff struct {
+    ID string `json:"id"`
+    // Title of the item in the new feed, or the old feed when removed.
+    Title string `json:"title,omitempty"`
+    // Whether the item is only in the new feed.
+    Added bool `json:"added,omitempty"`
+    // Whether the item is only in the old feed, or deleted in the new feed.
+    Removed bool `json:"removed,omitempty"`
+    // Publication dates in the old and new feed when re-dated. The new date
+    // is also given for added items.
+    OldPublished *time.Time `json:"old_published,omitempty"`
+    NewPublished *time.Time `json:"new_published,omitempty"`
+    // Fields which changed: "title", "link", "content" or "categories".
+    Changed []string `json:"changed,omitempty"`
+    // Line diff of the plain text of the content when it changed.
+    ContentDiff []DiffLine `json:"content_diff,omitempty"`
+}
+
+// Line of a text diff.
+type DiffLine struct {
+    // Operation of the line, "-" when removed, "+" when added and " " when
+    // unchanged.
+    Op string `json:"op"`
+    Text string `json:"text"`
+}
+
+// Whether the item was re-dated.
+func (d *ItemDiff) Redated() bool {
+    return d.OldPublished != nil
+}
+
+// Whether the feeds have the same items.
+func (d *FeedDiff) Empty() bool {
+    return len(d.Items) == 0
+}
+
+// Content of an item as HTML, the summary or text when there is no HTML
+// content.
+func itemHTML(item *FeedItem) string {
+    if item.ContentHTML != "" {
+        return item.ContentHTML
+    }
+    if item.Summary != "" {
+        return item.Summary
+    }
+    return html.EscapeString(item.ContentText)
+}
+
+// Render HTML content as plain text lines, one per paragraph, as read by
+// readers.
+func contentLines(s string) []string {
+    body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+    nodes, err := html.ParseFragment(strings.NewReader(s), body)
+    if err != nil {
+        return strings.Split(s, "\n")
+    }
+    var lines []string
+    for _, paragraph := range htmlhelper.RenderText(nodes, false).Paragraphs {
+        lines = append(lines, strings.Split(paragraph, "\n")...)
+    }
+    return lines
+}
+
+// Diff two lists of lines by their longest common subsequence.
+func diffLines(a []string, b []string) []DiffLine {
+    // lcs[i][j] is the length of the longest common subsequence of a[i:]
+    // and b[j:]
+    lcs := make([][]int, len(a) + 1)
+    for i := range lcs {
+        lcs[i] = make([]int, len(b) + 1)
+    }
+    for i := len(a) - 1; i >= 0; i-- {
+        for j := len(b) - 1; j >= 0; j-- {
+            if a[i] == b[j] {
+                lcs[i][j] = lcs[i+1][j+1] + 1
+            } else {
+                lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+            }
+        }
+    }
+    var diff []DiffLine
+    i, j := 0, 0
+    for i < len(a) && j < len(b) {
+        switch {
+        case a[i] == b[j]:
+            diff = append(diff, DiffLine{Op: " ", Text: a[i]})
+            i++
+            j++
+        case lcs[i+1][j] >= lcs[i][j+1]:
+            diff = append(diff, DiffLine{Op: "-", Text: a[i]})
+            i++
+        default:
+            diff = append(diff, DiffLine{Op: "+", Text: b[j]})
+            j++
+        }
+    }
+    for ; i < len(a); i++ {
+        diff = append(diff, DiffLine{Op: "-", Text: a[i]})
+    }
+    for ; j < len(b); j++ {
+        diff = append(diff, DiffLine{Op: "+", Text: b[j]})
+    }
+    return diff
+}
+
+// Compare an item of both feeds. Returns nil when the item is unchanged.
+func diffItem(old *FeedItem, new *FeedItem) *ItemDiff {
+    d := &ItemDiff{ID: new.ID, Title: new.Title}
+    if !old.Published.Equal(new.Published) {
+        oldPublished, newPublished := old.Published, new.Published
+        d.OldPublished = &oldPublished
+        d.NewPublished = &newPublished
+    }
+    if old.Title != new.Title {
+        d.Changed = append(d.Changed, "title")
+    }
+    if old.Link != new.Link {
+        d.Changed = append(d.Changed, "link")
+    }
+    if oldHTML, newHTML := itemHTML(old), itemHTML(new); oldHTML != newHTML {
+        d.Changed = append(d.Changed, "content")
+        d.ContentDiff = diffLines(contentLines(oldHTML), contentLines(newHTML))
+    }
+    if !slices.Equal(old.Categories, new.Categories) {
+        d.Changed = append(d.Changed, "categories")
+    }
+    if !d.Redated() && len(d.Changed) == 0 {
+        return nil
+    }
+    return d
+}
+
+// Compare the items of two feeds of any format, matched by ID. Items deleted
+// in the new feed (tombstones) are removed, tombstones are otherwise
+// ignored.
+func DiffFeeds(old *Feed, new *Feed) *FeedDiff {
+    oldItems := map[string]*FeedItem{}
+    for i := range old.Items {
+        if !old.Items[i].Deleted {
+            oldItems[old.Items[i].ID] = &old.Items[i]
+        }
+    }
+    diff := &FeedDiff{Items: []ItemDiff{}}
+    seen := map[string]bool{}
+    for i := range new.Items {
+        item := &new.Items[i]
+        if item.Deleted {
+            continue
+        }
+        seen[item.ID] = true
+        oldItem, ok := oldItems[item.ID]
+        if !ok {
+            added := ItemDiff{ID: item.ID, Title: item.Title, Added: true}
+            if !item.Published.IsZero() {
+                published := item.Published
+                added.NewPublished = &published
+            }
+            diff.Items = append(diff.Items, added)
+            continue
+        }
+        if d := diffItem(oldItem, item); d != nil {
+            diff.Items = append(diff.Items, *d)
+        }
+    }
+    for i := range old.Items {
+        item := &old.Items[i]
+        if item.Deleted || seen[item.ID] {
+            continue
+        }
+        diff.Items = append(diff.Items, ItemDiff{
+            ID: item.ID,
+            Title: item.Title,
+            Removed: true,
+        })
+    }
+    return diff
+}
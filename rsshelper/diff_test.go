@@ -0,0 +1,78 @@
+package rsshelper
+
+import (
+    "reflect"
+    "testing"
+)
+
+// Testing that items are reported added, removed, re-dated or changed with a
+// line diff of their plain text content.
+func TestDiffFeeds(t *testing.T) {
+    old := &Feed{Items: []FeedItem{
+        {ID: "a", Title: "a", ContentHTML: "<p>one</p><p>two</p><p>three</p>", Published: aprilUTC(1, 12, 0, 0)},
+        {ID: "b", Title: "b", Summary: "b", Published: aprilUTC(2, 12, 0, 0)},
+        {ID: "c", Title: "c", Published: aprilUTC(3, 12, 0, 0)},
+        {ID: "d", Title: "d", Published: aprilUTC(4, 12, 0, 0)},
+        {ID: "e", Deleted: true},
+    }}
+    new := &Feed{Items: []FeedItem{
+        {ID: "f", Title: "f", Published: aprilUTC(6, 12, 0, 0)},
+        {ID: "a", Title: "a", ContentHTML: "<p>one</p><p>2</p><p>three</p>", Published: aprilUTC(1, 12, 0, 0)},
+        {ID: "b", Title: "b", Summary: "b", Published: aprilUTC(5, 12, 0, 0)},
+        {ID: "c", Title: "c", Published: aprilUTC(3, 12, 0, 0)},
+        {ID: "d", Deleted: true},
+    }}
+    diff := DiffFeeds(old, new)
+
+    five, two, six := aprilUTC(5, 12, 0, 0), aprilUTC(2, 12, 0, 0), aprilUTC(6, 12, 0, 0)
+    expected := []ItemDiff{
+        {ID: "f", Title: "f", Added: true, NewPublished: &six},
+        {
+            ID: "a",
+            Title: "a",
+            Changed: []string{"content"},
+            ContentDiff: []DiffLine{{" ", "one"}, {"-", "two"}, {"+", "2"}, {" ", "three"}},
+        },
+        {ID: "b", Title: "b", OldPublished: &two, NewPublished: &five},
+        {ID: "d", Title: "d", Removed: true},
+    }
+    if !reflect.DeepEqual(diff.Items, expected) {
+        t.Errorf("unexpected diff\nexpected: %+v\ngot:      %+v", expected, diff.Items)
+    }
+    if DiffFeeds(old, old).Empty() != true {
+        t.Errorf("expected a feed to have no differences with itself")
+    }
+}
+
+// Testing that a change to the xhtml content of an Atom entry is found once
+// both feeds are decoded.
+func TestDiffFeedsAtomXHTML(t *testing.T) {
+    atom := func(content string) *Feed {
+        t.Helper()
+        feed, err := Decode([]byte(`<feed xmlns="http://www.w3.org/2005/Atom">
+    <id>http://yarrie.net/microblog</id>
+    <title>yarrie</title>
+    <updated>2025-04-14T12:00:00Z</updated>
+    <entry>
+        <id>http://yarrie.net/microblog#second</id>
+        <title>second</title>
+        <updated>2025-04-14T12:00:00Z</updated>
+        <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml">` + content + `</div></content>
+    </entry>
+</feed>`))
+        if err != nil {
+            t.Fatalf("failed to decode atom: %s", err)
+        }
+        return feed
+    }
+    diff := DiffFeeds(atom("<p>one</p><p>two</p>"), atom("<p>one</p><p>2</p>"))
+    expected := []ItemDiff{{
+        ID: "http://yarrie.net/microblog#second",
+        Title: "second",
+        Changed: []string{"content"},
+        ContentDiff: []DiffLine{{" ", "one"}, {"-", "two"}, {"+", "2"}},
+    }}
+    if !reflect.DeepEqual(diff.Items, expected) {
+        t.Errorf("unexpected diff\nexpected: %+v\ngot:      %+v", expected, diff.Items)
+    }
+}
@@ -2,18 +2,108 @@ package rsshelper
 
 import (
     "encoding/xml"
+    "time"
 )
 
+// Format an RSS date, empty for the zero time so that the element is omitted.
+func formatRssDate(t time.Time) string {
+    if t.IsZero() {
+        return ""
+    }
+    return t.Format(rssDateLayout)
+}
+
+// Namespace prefixes declared by the root element of encoded RSS feeds.
+// encoding/xml declares a default namespace on every namespaced element
+// rather than using a prefix, so namespaced elements are encoded with these
+// prefixes by name, e.g. "atom:link".
+var rssNamespaces = []xml.Attr{
+    {Name: xml.Name{Local: "xmlns:atom"}, Value: AtomNamespace},
+    {Name: xml.Name{Local: "xmlns:content"}, Value: ContentNamespace},
+    {Name: xml.Name{Local: "xmlns:dc"}, Value: DublinCoreNamespace},
+    {Name: xml.Name{Local: "xmlns:media"}, Value: MediaNamespace},
+}
+
+func (r *RSS) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
+    type Alias RSS
+    start.Name = xml.Name{Local: "rss"}
+    start.Attr = append(start.Attr, rssNamespaces...)
+    // feed history is only declared by archive documents
+    if r.Channel.Archive != nil {
+        start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:fh"}, Value: HistoryNamespace})
+    }
+    return e.EncodeElement((*Alias)(r), start)
+}
+
+func (c *Channel) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
+    type Alias Channel
+    // namespaced elements are encoded by the prefixed fields instead
+    unprefixed := *c
+    unprefixed.AtomLinks = nil
+    unprefixed.Archive = nil
+    aux := &struct{
+        PubDate string `xml:"pubDate,omitempty"`
+        LastBuildDate string `xml:"lastBuildDate,omitempty"`
+        AtomLinks []AtomLink `xml:"atom:link"`
+        Archive *HistoryArchive `xml:"fh:archive,omitempty"`
+        *Alias
+    }{
+        PubDate: formatRssDate(c.PubDate),
+        LastBuildDate: formatRssDate(c.LastBuildDate),
+        AtomLinks: c.AtomLinks,
+        Archive: c.Archive,
+        Alias: (*Alias)(&unprefixed),
+    }
+    return e.EncodeElement(aux, start)
+}
+
 func (i *Item) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
-    formattedDate := i.PubDate.Format("Mon, 02 Jan 2006 15:04:05 -0700")
+    formattedDate := formatRssDate(i.PubDate)
     type Alias Item
+    // namespaced elements are encoded by the prefixed fields instead
+    unprefixed := *i
+    unprefixed.Creator = ""
+    unprefixed.ContentEncoded = nil
+    unprefixed.MediaContents = nil
+    unprefixed.Updated = nil
     aux := &struct{
-        PubDate string `xml:"pubDate"`
+        PubDate string `xml:"pubDate,omitempty"`
+        Updated *time.Time `xml:"atom:updated,omitempty"`
+        Creator string `xml:"dc:creator,omitempty"`
+        ContentEncoded *CDATA `xml:"content:encoded,omitempty"`
+        MediaContents []MediaContent `xml:"media:content"`
         *Alias
     }{
         PubDate: formattedDate,
-        Alias: (*Alias)(i),
+        Updated: i.Updated,
+        Creator: i.Creator,
+        ContentEncoded: i.ContentEncoded,
+        MediaContents: i.MediaContents,
+        Alias: (*Alias)(&unprefixed),
     } 
     return e.EncodeElement(aux, start)
 }
 
+func (m *MediaContent) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
+    type Alias MediaContent
+    // namespaced elements are encoded by the prefixed fields instead
+    unprefixed := *m
+    unprefixed.Description = ""
+    aux := &struct{
+        Description string `xml:"media:description,omitempty"`
+        *Alias
+    }{
+        Description: m.Description,
+        Alias: (*Alias)(&unprefixed),
+    }
+    return e.EncodeElement(aux, start)
+}
+
+// Encode the RSS feed as indented XML with an XML declaration.
+func Encode(rss *RSS) ([]byte, error) {
+    data, err := xml.MarshalIndent(rss, "", "    ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(xml.Header), data...), nil
+}
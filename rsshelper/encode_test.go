@@ -0,0 +1,117 @@
+package rsshelper
+
+import (
+    "encoding/xml"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+)
+
+// Testing that a channel with every optional element encodes with RSS dates
+// and the atom self link, and decodes to the same channel.
+func TestEncodeChannel(t *testing.T) {
+    published := time.Date(2025, time.April, 14, 12, 26, 44, 0, time.FixedZone("", 3600))
+    channel := Channel{
+        AtomLinks: []AtomLink{{Href: "http://yarrie.net/microblog/rss.xml", Rel: "self", Type: "application/rss+xml"}},
+        Title: "yarrie",
+        Link: "http://yarrie.net/microblog",
+        Description: "yarrie's microblog",
+        Language: "en-GB",
+        Copyright: "yarrie",
+        ManagingEditor: "editor@yarrie.net (yarrie)",
+        WebMaster: "web@yarrie.net (yarrie)",
+        PubDate: published,
+        LastBuildDate: published.Add(time.Hour),
+        Categories: []Category{{Value: "music"}, {Domain: "http://yarrie.net/tags", Value: "news"}},
+        Generator: "yarrienet-tools",
+        Docs: "https://www.rssboard.org/rss-specification",
+        Cloud: &Cloud{Domain: "rpc.yarrie.net", Port: 80, Path: "/RPC2", RegisterProcedure: "notify", Protocol: "xml-rpc"},
+        TTL: 60,
+        Image: &Image{URL: "http://yarrie.net/icon.png", Title: "yarrie", Link: "http://yarrie.net/microblog", Width: 88, Height: 31},
+        Rating: "(PICS-1.1)",
+        TextInput: &TextInput{Title: "search", Description: "search posts", Name: "q", Link: "http://yarrie.net/search"},
+        SkipHours: &SkipHours{Hours: []int{0, 1}},
+        SkipDays: &SkipDays{Days: []string{"Saturday"}},
+    }
+
+    data, err := Encode(&RSS{Version: "2.0", Channel: channel})
+    if err != nil {
+        t.Fatalf("failed to encode rss: %s", err)
+    }
+    s := string(data)
+    expected := []string{
+        `<?xml version="1.0" encoding="UTF-8"?>`,
+        `<pubDate>Mon, 14 Apr 2025 12:26:44 +0100</pubDate>`,
+        `<lastBuildDate>Mon, 14 Apr 2025 13:26:44 +0100</lastBuildDate>`,
+        `<rss xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:media="http://search.yahoo.com/mrss/" version="2.0">`,
+        `<atom:link href="http://yarrie.net/microblog/rss.xml" rel="self" type="application/rss+xml"></atom:link>`,
+        `<skipHours>`,
+        `<hour>1</hour>`,
+    }
+    for _, e := range expected {
+        if !strings.Contains(s, e) {
+            t.Errorf("expected encoded rss to contain '%s':\n%s", e, s)
+        }
+    }
+
+    var decoded RSS
+    if err := xml.Unmarshal(data, &decoded); err != nil {
+        t.Fatalf("failed to decode encoded rss: %s", err)
+    }
+    if !decoded.Channel.PubDate.Equal(channel.PubDate) || !decoded.Channel.LastBuildDate.Equal(channel.LastBuildDate) {
+        t.Errorf("expected channel dates to round trip, got %s and %s", decoded.Channel.PubDate, decoded.Channel.LastBuildDate)
+    }
+    decoded.Channel.PubDate = channel.PubDate
+    decoded.Channel.LastBuildDate = channel.LastBuildDate
+    if !reflect.DeepEqual(decoded.Channel, channel) {
+        t.Errorf("round trip changed the channel\nexpected: %+v\ngot:      %+v", channel, decoded.Channel)
+    }
+}
+
+// Testing that namespaced item elements are encoded with the prefixes declared
+// by the root element and decode by their namespace.
+func TestEncodeItemNamespaces(t *testing.T) {
+    rss := &RSS{Version: "2.0", Channel: Channel{Items: []Item{{
+        Creator: "yarrie",
+        ContentEncoded: &CDATA{Text: "<p>a &amp; b]]>c</p>"},
+        MediaContents: []MediaContent{{URL: "http://yarrie.net/a.png", Type: "image/png", Medium: "image", Width: 2, Height: 1, Description: "a cat"}},
+    }}}}
+    data, err := Encode(rss)
+    if err != nil {
+        t.Fatalf("failed to encode rss: %s", err)
+    }
+    s := string(data)
+    for _, e := range []string{`<dc:creator>yarrie</dc:creator>`, `<content:encoded><![CDATA[<p>a &amp; b]]]]><![CDATA[>c</p>]]></content:encoded>`, `<media:content url="http://yarrie.net/a.png" type="image/png" medium="image" width="2" height="1">`, `<media:description>a cat</media:description>`} {
+        if !strings.Contains(s, e) {
+            t.Errorf("expected encoded rss to contain '%s':\n%s", e, s)
+        }
+    }
+
+    var decoded RSS
+    if err := xml.Unmarshal(data, &decoded); err != nil {
+        t.Fatalf("failed to decode encoded rss: %s", err)
+    }
+    item := decoded.Channel.Items[0]
+    if item.Creator != "yarrie" {
+        t.Errorf("expected creator 'yarrie' not '%s'", item.Creator)
+    }
+    if item.ContentEncoded == nil || item.ContentEncoded.Text != "<p>a &amp; b]]>c</p>" {
+        t.Errorf("expected content:encoded to decode exactly, got %v", item.ContentEncoded)
+    }
+    if !reflect.DeepEqual(item.MediaContents, rss.Channel.Items[0].MediaContents) {
+        t.Errorf("expected media:content to round trip, got %+v", item.MediaContents)
+    }
+}
+
+// Testing that optional item elements are omitted when empty.
+func TestEncodeItemOmitsEmpty(t *testing.T) {
+    item := Item{GUID: &GUID{Value: "http://yarrie.net/microblog#a"}}
+    data, err := xml.Marshal(&item)
+    if err != nil {
+        t.Fatalf("failed to encode item: %s", err)
+    }
+    if s := string(data); s != `<Item><guid>http://yarrie.net/microblog#a</guid></Item>` {
+        t.Errorf("unexpected encoded item %s", s)
+    }
+}
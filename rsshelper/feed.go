@@ -0,0 +1,481 @@
+package rsshelper
+
+import (
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "strings"
+    "time"
+    "unicode/utf8"
+)
+
+// Namespace of RDF, the root element of RSS 1.0.
+const RDFNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// Namespace of RSS 1.0 elements.
+const RSS1Namespace = "http://purl.org/rss/1.0/"
+
+// Dialects of feeds read by Decode.
+const (
+    FormatRSS = "rss"
+    FormatRDF = "rdf"
+    FormatAtom = "atom"
+    FormatJSON = "json"
+)
+
+// Feed of any dialect read by Decode, with the elements the dialects have in
+// common. Missing elements are left empty.
+type Feed struct {
+    // Dialect of the feed, FormatRSS, FormatRDF, FormatAtom or FormatJSON.
+    Format string
+    // Version of the dialect, e.g. "0.91" or "2.0" for RSS.
+    Version string
+    Title string
+    // URL of the website of the feed.
+    Link string
+    // URL the feed itself is published at.
+    FeedURL string
+    Description string
+    Language string
+    Copyright string
+    Generator string
+    ImageURL string
+    // Names or email addresses of the authors.
+    Authors []string
+    Categories []string
+    Published time.Time
+    // Most recent time the content of the feed changed.
+    Updated time.Time
+    Items []FeedItem
+}
+
+// Item of a Feed, an entry of Atom.
+type FeedItem struct {
+    // Unique ID of the item, the guid of RSS or otherwise the link.
+    ID string
+    Title string
+    Link string
+    // Description or summary of the item. May contain HTML in RSS.
+    Summary string
+    // Full HTML content, e.g. content:encoded of RSS.
+    ContentHTML string
+    // Full plain text content of Atom text content and JSON Feed.
+    ContentText string
+    // Names or email addresses of the authors.
+    Authors []string
+    Categories []string
+    Published time.Time
+    Updated time.Time
+    Enclosures []FeedEnclosure
+    // Whether the item is a tombstone of a deleted item, from Atom
+    // deleted-entry or the "_yarrienet" extension of JSON Feed.
+    Deleted bool
+}
+
+// Media file attached to a FeedItem.
+type FeedEnclosure struct {
+    URL string
+    // MIME type.
+    Type string
+    // Size in bytes, 0 when unknown.
+    Length int64
+}
+
+// Decode a feed in any of RSS 0.91 to 2.0, RSS 1.0 (RDF), Atom 1.0 or JSON
+// Feed 1.0 and 1.1, recognised by its root element. Returns an error when the
+// dialect is unknown or the feed is malformed.
+func Decode(data []byte) (*Feed, error) {
+    trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+    if len(trimmed) > 0 && trimmed[0] == '{' {
+        return decodeJSONFeed(trimmed)
+    }
+
+    root, err := rootElement(data)
+    if err != nil {
+        return nil, err
+    }
+    switch {
+    case root.Name.Local == "rss":
+        return decodeRSS(data)
+    case root.Name.Local == "RDF" && root.Name.Space == RDFNamespace:
+        return decodeRDF(data)
+    case root.Name.Local == "feed" && root.Name.Space == AtomNamespace:
+        return decodeAtomFeed(data)
+    }
+    return nil, fmt.Errorf("unknown feed format with root element <%s> (expected rss, rdf:RDF, atom feed or json feed)", root.Name.Local)
+}
+
+// Code points of the bytes 0x80 to 0x9f in windows-1252, where Latin-1 has
+// control characters. The five bytes windows-1252 leaves undefined are kept
+// as their Latin-1 control characters.
+var windows1252High = [32]rune{
+    '\u20ac', '\u0081', '\u201a', '\u0192', '\u201e', '\u2026', '\u2020', '\u2021',
+    '\u02c6', '\u2030', '\u0160', '\u2039', '\u0152', '\u008d', '\u017d', '\u008f',
+    '\u0090', '\u2018', '\u2019', '\u201c', '\u201d', '\u2022', '\u2013', '\u2014',
+    '\u02dc', '\u2122', '\u0161', '\u203a', '\u0153', '\u009d', '\u017e', '\u0178',
+}
+
+// Read a Latin-1 document as UTF-8, each byte is its code point, or a
+// windows-1252 document when high is set, which maps the bytes 0x80 to 0x9f.
+type latin1Reader struct {
+    r io.Reader
+    // code points of the bytes 0x80 to 0x9f, nil for Latin-1
+    high *[32]rune
+    // converted bytes not yet read
+    pending []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+    if len(l.pending) == 0 {
+        buf := make([]byte, len(p))
+        n, err := l.r.Read(buf)
+        for _, b := range buf[:n] {
+            r := rune(b)
+            if l.high != nil && b >= 0x80 && b <= 0x9f {
+                r = l.high[b-0x80]
+            }
+            l.pending = utf8.AppendRune(l.pending, r)
+        }
+        if n == 0 {
+            return 0, err
+        }
+    }
+    n := copy(p, l.pending)
+    l.pending = l.pending[n:]
+    return n, nil
+}
+
+// Read documents in the charsets older feeds are commonly declared in.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+    switch strings.ToLower(charset) {
+    case "utf-8", "us-ascii", "ascii":
+        return input, nil
+    case "iso-8859-1", "latin1", "latin-1":
+        return &latin1Reader{r: input}, nil
+    case "windows-1252", "cp1252":
+        return &latin1Reader{r: input, high: &windows1252High}, nil
+    }
+    return nil, fmt.Errorf("unsupported charset '%s'", charset)
+}
+
+// Decode an XML feed leniently, with HTML entities allowed (e.g. &nbsp; in
+// RSS 0.91).
+func unmarshalFeed(data []byte, v any) error {
+    d := xml.NewDecoder(bytes.NewReader(data))
+    d.Strict = false
+    d.Entity = xml.HTMLEntity
+    d.CharsetReader = charsetReader
+    return d.Decode(v)
+}
+
+// Find the root element of an XML document.
+func rootElement(data []byte) (*xml.StartElement, error) {
+    d := xml.NewDecoder(bytes.NewReader(data))
+    d.Strict = false
+    d.CharsetReader = charsetReader
+    for {
+        token, err := d.Token()
+        if err != nil {
+            return nil, fmt.Errorf("no root element: %w", err)
+        }
+        if start, ok := token.(xml.StartElement); ok {
+            return &start, nil
+        }
+    }
+}
+
+// Append the value to the list when it is not empty.
+func appendNonEmpty(list []string, values ...string) []string {
+    for _, v := range values {
+        if v = strings.TrimSpace(v); v != "" {
+            list = append(list, v)
+        }
+    }
+    return list
+}
+
+// Read RSS 0.91, 0.92 and 2.0.
+func decodeRSS(data []byte) (*Feed, error) {
+    var rss RSS
+    if err := unmarshalFeed(data, &rss); err != nil {
+        return nil, err
+    }
+    c := rss.Channel
+    feed := &Feed{
+        Format: FormatRSS,
+        Version: rss.Version,
+        Title: c.Title,
+        Link: c.Link,
+        Description: c.Description,
+        Language: c.Language,
+        Copyright: c.Copyright,
+        Generator: c.Generator,
+        Authors: appendNonEmpty(nil, c.ManagingEditor),
+        Published: c.PubDate,
+        Updated: c.LastBuildDate,
+    }
+    for _, link := range c.AtomLinks {
+        if link.Rel == "self" {
+            feed.FeedURL = link.Href
+        }
+    }
+    if c.Image != nil {
+        feed.ImageURL = c.Image.URL
+    }
+    for _, category := range c.Categories {
+        feed.Categories = appendNonEmpty(feed.Categories, category.Value)
+    }
+    for _, i := range c.Items {
+        item := FeedItem{
+            Title: i.Title,
+            Link: i.Link,
+            Summary: i.Description,
+            Authors: appendNonEmpty(nil, i.Author, i.Creator),
+            Published: i.PubDate,
+        }
+        if i.Updated != nil {
+            item.Updated = *i.Updated
+        }
+        item.ID = i.Link
+        if i.GUID != nil && i.GUID.Value != "" {
+            item.ID = i.GUID.Value
+        }
+        if i.ContentEncoded != nil {
+            item.ContentHTML = i.ContentEncoded.Text
+        }
+        for _, category := range i.Categories {
+            item.Categories = appendNonEmpty(item.Categories, category.Value)
+        }
+        if i.Enclosure != nil {
+            item.Enclosures = append(item.Enclosures, FeedEnclosure{
+                URL: i.Enclosure.URL,
+                Type: i.Enclosure.Type,
+                Length: i.Enclosure.Length,
+            })
+        }
+        feed.Items = append(feed.Items, item)
+    }
+    return feed, nil
+}
+
+// RSS 1.0 document, the channel, image and items are siblings within
+// rdf:RDF.
+type rdfDocument struct {
+    Channel struct {
+        About string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+        Title string `xml:"http://purl.org/rss/1.0/ title"`
+        Link string `xml:"http://purl.org/rss/1.0/ link"`
+        Description string `xml:"http://purl.org/rss/1.0/ description"`
+        Language string `xml:"http://purl.org/dc/elements/1.1/ language"`
+        Rights string `xml:"http://purl.org/dc/elements/1.1/ rights"`
+        Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+        Date string `xml:"http://purl.org/dc/elements/1.1/ date"`
+    } `xml:"http://purl.org/rss/1.0/ channel"`
+    Image struct {
+        URL string `xml:"http://purl.org/rss/1.0/ url"`
+    } `xml:"http://purl.org/rss/1.0/ image"`
+    Items []struct {
+        About string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+        Title string `xml:"http://purl.org/rss/1.0/ title"`
+        Link string `xml:"http://purl.org/rss/1.0/ link"`
+        Description string `xml:"http://purl.org/rss/1.0/ description"`
+        ContentEncoded string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+        Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+        Subjects []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
+        Date string `xml:"http://purl.org/dc/elements/1.1/ date"`
+    } `xml:"http://purl.org/rss/1.0/ item"`
+}
+
+// Read RSS 1.0 (RDF), dates are taken from dc:date.
+func decodeRDF(data []byte) (*Feed, error) {
+    var doc rdfDocument
+    if err := unmarshalFeed(data, &doc); err != nil {
+        return nil, err
+    }
+    c := doc.Channel
+    feed := &Feed{
+        Format: FormatRDF,
+        Version: "1.0",
+        Title: c.Title,
+        Link: c.Link,
+        FeedURL: c.About,
+        Description: c.Description,
+        Language: c.Language,
+        Copyright: c.Rights,
+        ImageURL: doc.Image.URL,
+        Authors: appendNonEmpty(nil, c.Creator),
+    }
+    // dates are optional, invalid dates are left as zero here and for items
+    feed.Updated, _ = ParseDate(c.Date)
+    for _, i := range doc.Items {
+        item := FeedItem{
+            ID: i.About,
+            Title: i.Title,
+            Link: i.Link,
+            Summary: i.Description,
+            ContentHTML: i.ContentEncoded,
+            Authors: appendNonEmpty(nil, i.Creator),
+            Categories: appendNonEmpty(nil, i.Subjects...),
+        }
+        if item.ID == "" {
+            item.ID = i.Link
+        }
+        item.Published, _ = ParseDate(i.Date)
+        feed.Items = append(feed.Items, item)
+    }
+    return feed, nil
+}
+
+// Name of an Atom person, otherwise the email address.
+func atomPersonName(person *AtomPerson) string {
+    if person == nil {
+        return ""
+    }
+    if person.Name != "" {
+        return person.Name
+    }
+    return person.Email
+}
+
+// Read Atom 1.0, tombstones are given as deleted items.
+func decodeAtomFeed(data []byte) (*Feed, error) {
+    atom, err := DecodeAtom(data)
+    if err != nil {
+        return nil, err
+    }
+    feed := &Feed{
+        Format: FormatAtom,
+        Version: "1.0",
+        Title: atom.Title,
+        Description: atom.Subtitle,
+        Authors: appendNonEmpty(nil, atomPersonName(atom.Author)),
+        Updated: atom.Updated,
+    }
+    for _, link := range atom.Links {
+        switch link.Rel {
+        case "", "alternate":
+            feed.Link = link.Href
+        case "self":
+            feed.FeedURL = link.Href
+        }
+    }
+    for _, e := range atom.Entries {
+        item := FeedItem{
+            ID: e.ID,
+            Title: e.Title,
+            Authors: appendNonEmpty(nil, atomPersonName(e.Author)),
+            Updated: e.Updated,
+        }
+        if e.Published != nil {
+            item.Published = *e.Published
+        }
+        for _, link := range e.Links {
+            switch link.Rel {
+            case "", "alternate":
+                item.Link = link.Href
+            case "enclosure":
+                item.Enclosures = append(item.Enclosures, FeedEnclosure{URL: link.Href, Type: link.Type})
+            }
+        }
+        for _, category := range e.Categories {
+            item.Categories = appendNonEmpty(item.Categories, category.Term)
+        }
+        if e.Summary != nil {
+            item.Summary = e.Summary.Body
+        }
+        if e.Content != nil {
+            if e.Content.Type == "html" || e.Content.Type == "xhtml" {
+                item.ContentHTML = e.Content.Body
+            } else {
+                item.ContentText = e.Content.Body
+            }
+        }
+        feed.Items = append(feed.Items, item)
+    }
+    for _, deleted := range atom.DeletedEntries {
+        feed.Items = append(feed.Items, FeedItem{
+            ID: deleted.Ref,
+            Updated: deleted.When,
+            Deleted: true,
+        })
+    }
+    return feed, nil
+}
+
+// Name of a JSON Feed author, otherwise the URL.
+func jsonAuthorName(author JSONAuthor) string {
+    if author.Name != "" {
+        return author.Name
+    }
+    return author.URL
+}
+
+// Read JSON Feed 1.0 and 1.1.
+func decodeJSONFeed(data []byte) (*Feed, error) {
+    j, err := DecodeJSONFeed(data)
+    if err != nil {
+        return nil, err
+    }
+    if !strings.HasPrefix(j.Version, "https://jsonfeed.org/version/") {
+        return nil, fmt.Errorf("unknown json feed version '%s'", j.Version)
+    }
+    feed := &Feed{
+        Format: FormatJSON,
+        Version: strings.TrimPrefix(j.Version, "https://jsonfeed.org/version/"),
+        Title: j.Title,
+        Link: j.HomePageURL,
+        FeedURL: j.FeedURL,
+        Description: j.Description,
+        Language: j.Language,
+    }
+    authors := j.Authors
+    if j.Author != nil {
+        authors = append(authors, *j.Author)
+    }
+    for _, author := range authors {
+        feed.Authors = appendNonEmpty(feed.Authors, jsonAuthorName(author))
+    }
+    for _, i := range j.Items {
+        item := FeedItem{
+            ID: i.ID,
+            Title: i.Title,
+            Link: i.URL,
+            Summary: i.Summary,
+            ContentHTML: i.ContentHTML,
+            ContentText: i.ContentText,
+            Categories: appendNonEmpty(nil, i.Tags...),
+        }
+        authors := i.Authors
+        if i.Author != nil {
+            authors = append(authors, *i.Author)
+        }
+        for _, author := range authors {
+            item.Authors = appendNonEmpty(item.Authors, jsonAuthorName(author))
+        }
+        if i.DatePublished != nil {
+            item.Published = *i.DatePublished
+        }
+        if i.DateModified != nil {
+            item.Updated = *i.DateModified
+        }
+        for _, attachment := range i.Attachments {
+            item.Enclosures = append(item.Enclosures, FeedEnclosure{
+                URL: attachment.URL,
+                Type: attachment.MimeType,
+                Length: attachment.SizeInBytes,
+            })
+        }
+        feed.Items = append(feed.Items, item)
+    }
+    if j.Yarrienet != nil {
+        for _, deleted := range j.Yarrienet.Deleted {
+            item := FeedItem{ID: deleted.ID, Deleted: true}
+            if deleted.DateDeleted != nil {
+                item.Updated = *deleted.DateDeleted
+            }
+            feed.Items = append(feed.Items, item)
+        }
+    }
+    return feed, nil
+}
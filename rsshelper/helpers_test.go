@@ -0,0 +1,20 @@
+package rsshelper
+
+import (
+    "time"
+)
+
+// Date in UTC on 2025-04-dd at hh:mm:ss.
+func aprilUTC(day int, hour int, min int, sec int) time.Time {
+    return time.Date(2025, time.April, day, hour, min, sec, 0, time.UTC)
+}
+
+// Convert the dates of a feed to UTC so that feeds can be compared deeply.
+func feedInUTC(feed *Feed) {
+    feed.Published = feed.Published.UTC()
+    feed.Updated = feed.Updated.UTC()
+    for i := range feed.Items {
+        feed.Items[i].Published = feed.Items[i].Published.UTC()
+        feed.Items[i].Updated = feed.Items[i].Updated.UTC()
+    }
+}
@@ -0,0 +1,138 @@
+package rsshelper
+
+import (
+    "bytes"
+    "encoding/json"
+    "time"
+)
+
+// Version URL of JSON Feed 1.1.
+const JSONFeedVersion = "https://jsonfeed.org/version/1.1"
+
+// JSON Feed 1.1 feed, see https://jsonfeed.org/version/1.1.
+type JSONFeed struct {
+    Version string `json:"version"`
+    Title string `json:"title"`
+    HomePageURL string `json:"home_page_url,omitempty"`
+    // URL the feed itself is published at.
+    FeedURL string `json:"feed_url,omitempty"`
+    Description string `json:"description,omitempty"`
+    // Language of the feed as an RFC 5646 tag, e.g. "en-GB".
+    Language string `json:"language,omitempty"`
+    Authors []JSONAuthor `json:"authors,omitempty"`
+    // Author of JSON Feed 1.0, superseded by Authors.
+    Author *JSONAuthor `json:"author,omitempty"`
+    // URL of the feed of the next, older, items when paginated.
+    NextURL string `json:"next_url,omitempty"`
+    Items []JSONItem `json:"items"`
+    // Extension of this tool, present when posts were deleted.
+    Yarrienet *JSONFeedExtension `json:"_yarrienet,omitempty"`
+}
+
+// Item of a JSON Feed. One of the HTML or text content must be present, the
+// HTML content is always encoded.
+type JSONItem struct {
+    // Unique ID of the item, a permalink when possible.
+    ID string `json:"id"`
+    URL string `json:"url,omitempty"`
+    Title string `json:"title,omitempty"`
+    ContentHTML string `json:"content_html"`
+    ContentText string `json:"content_text,omitempty"`
+    Summary string `json:"summary,omitempty"`
+    DatePublished *time.Time `json:"date_published,omitempty"`
+    DateModified *time.Time `json:"date_modified,omitempty"`
+    Authors []JSONAuthor `json:"authors,omitempty"`
+    // Author of JSON Feed 1.0, superseded by Authors.
+    Author *JSONAuthor `json:"author,omitempty"`
+    Tags []string `json:"tags,omitempty"`
+    Attachments []JSONAttachment `json:"attachments,omitempty"`
+}
+
+// Author of a JSON Feed or item.
+type JSONAuthor struct {
+    Name string `json:"name,omitempty"`
+    URL string `json:"url,omitempty"`
+    Avatar string `json:"avatar,omitempty"`
+}
+
+// Related resource of a JSON Feed item, e.g. an image or audio file.
+type JSONAttachment struct {
+    URL string `json:"url"`
+    MimeType string `json:"mime_type"`
+    Title string `json:"title,omitempty"`
+    SizeInBytes int64 `json:"size_in_bytes,omitempty"`
+    DurationInSeconds int64 `json:"duration_in_seconds,omitempty"`
+}
+
+// Custom "_yarrienet" extension of a feed. JSON Feed has no deleted items so
+// deleted posts are listed by this extension, outside of the items which
+// readers would show.
+type JSONFeedExtension struct {
+    Deleted []JSONDeletedItem `json:"deleted,omitempty"`
+}
+
+// Item deleted from a JSON Feed, see JSONFeedExtension.
+type JSONDeletedItem struct {
+    // ID the item had.
+    ID string `json:"id"`
+    DateDeleted *time.Time `json:"date_deleted,omitempty"`
+}
+
+// Encode the JSON Feed as indented JSON. HTML characters are not escaped as
+// unicode sequences so that content stays readable.
+func EncodeJSONFeed(feed *JSONFeed) ([]byte, error) {
+    var b bytes.Buffer
+    enc := json.NewEncoder(&b)
+    enc.SetEscapeHTML(false)
+    enc.SetIndent("", "    ")
+    if err := enc.Encode(feed); err != nil {
+        return nil, err
+    }
+    return bytes.TrimSuffix(b.Bytes(), []byte("\n")), nil
+}
+
+// Decode a JSON Feed item with its dates in any form ParseDate reads, an
+// invalid date is left out rather than failing the whole feed.
+func (i *JSONItem) UnmarshalJSON(data []byte) error {
+    type Alias JSONItem
+    aux := &struct{
+        DatePublished string `json:"date_published"`
+        DateModified string `json:"date_modified"`
+        *Alias
+    }{
+        Alias: (*Alias)(i),
+    }
+    if err := json.Unmarshal(data, aux); err != nil {
+        return err
+    }
+    i.DatePublished = parseOptionalDate(aux.DatePublished)
+    i.DateModified = parseOptionalDate(aux.DateModified)
+    return nil
+}
+
+// Decode a deleted item with its date in any form ParseDate reads, see
+// JSONItem.UnmarshalJSON.
+func (i *JSONDeletedItem) UnmarshalJSON(data []byte) error {
+    type Alias JSONDeletedItem
+    aux := &struct{
+        DateDeleted string `json:"date_deleted"`
+        *Alias
+    }{
+        Alias: (*Alias)(i),
+    }
+    if err := json.Unmarshal(data, aux); err != nil {
+        return err
+    }
+    i.DateDeleted = parseOptionalDate(aux.DateDeleted)
+    return nil
+}
+
+// Decode a JSON Feed.
+func DecodeJSONFeed(data []byte) (*JSONFeed, error) {
+    var feed JSONFeed
+    err := json.Unmarshal(data, &feed)
+    if err != nil {
+        return nil, err
+    }
+    return &feed, nil
+}
@@ -0,0 +1,124 @@
+package rsshelper
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+var exampleJSONFeed = `{
+    "version": "https://jsonfeed.org/version/1.1",
+    "title": "yarrie",
+    "home_page_url": "http://yarrie.net/microblog",
+    "feed_url": "http://yarrie.net/microblog/feed.json",
+    "language": "en-GB",
+    "items": [
+        {
+            "id": "http://yarrie.net/microblog#exampleid",
+            "url": "http://yarrie.net/microblog#exampleid",
+            "content_html": "<p>hello</p>",
+            "content_text": "hello",
+            "date_published": "2025-04-14T12:26:44+01:00",
+            "tags": ["a", "b"],
+            "attachments": [{"url": "http://yarrie.net/a.mp3", "mime_type": "audio/mpeg"}]
+        }
+    ],
+    "_yarrienet": {
+        "deleted": [{"id": "http://yarrie.net/microblog#gone", "date_deleted": "2025-04-15T08:00:00Z"}]
+    }
+}`
+
+// Testing decoding of a JSON Feed with an item and a deleted item.
+func TestDecodeJSONFeed(t *testing.T) {
+    feed, err := DecodeJSONFeed([]byte(exampleJSONFeed))
+    if err != nil {
+        t.Fatalf("failed to decode json feed: %s", err)
+    }
+    if feed.Version != JSONFeedVersion || feed.Language != "en-GB" {
+        t.Errorf("unexpected version '%s' or language '%s'", feed.Version, feed.Language)
+    }
+    if len(feed.Items) != 1 {
+        t.Fatalf("expected 1 item, got %d", len(feed.Items))
+    }
+    item := feed.Items[0]
+    expectedPublished := aprilUTC(14, 11, 26, 44)
+    if item.DatePublished == nil || !item.DatePublished.Equal(expectedPublished) {
+        t.Errorf("expected date published %s not %v", expectedPublished, item.DatePublished)
+    }
+    if len(item.Attachments) != 1 || item.Attachments[0].MimeType != "audio/mpeg" {
+        t.Errorf("unexpected attachments %v", item.Attachments)
+    }
+    if feed.Yarrienet == nil || len(feed.Yarrienet.Deleted) != 1 || feed.Yarrienet.Deleted[0].ID != "http://yarrie.net/microblog#gone" {
+        t.Errorf("expected the extension to list the deleted item, got %+v", feed.Yarrienet)
+    }
+}
+
+// Testing that item dates in RFC 822 are read and invalid dates left out
+// rather than failing the feed.
+func TestDecodeJSONFeedDates(t *testing.T) {
+    data := strings.Replace(exampleJSONFeed, `"2025-04-14T12:26:44+01:00"`, `"Mon, 14 Apr 2025 12:26:44 +0100", "date_modified": "yesterday"`, 1)
+    data = strings.Replace(data, `"2025-04-15T08:00:00Z"`, `"soon"`, 1)
+    feed, err := DecodeJSONFeed([]byte(data))
+    if err != nil {
+        t.Fatalf("failed to decode json feed: %s", err)
+    }
+    item := feed.Items[0]
+    if item.DatePublished == nil || !item.DatePublished.Equal(aprilUTC(14, 11, 26, 44)) {
+        t.Errorf("expected the RFC 822 date published to be read, got %v", item.DatePublished)
+    }
+    if item.DateModified != nil {
+        t.Errorf("expected the invalid date modified to be left out, got %v", item.DateModified)
+    }
+    if feed.Yarrienet == nil || len(feed.Yarrienet.Deleted) != 1 || feed.Yarrienet.Deleted[0].DateDeleted != nil {
+        t.Errorf("expected the deleted item without its invalid date, got %+v", feed.Yarrienet)
+    }
+}
+
+// Testing that an encoded JSON Feed decodes to the same feed.
+func TestJSONFeedRoundTrip(t *testing.T) {
+    published := aprilUTC(14, 11, 26, 44)
+    feed := &JSONFeed{
+        Version: JSONFeedVersion,
+        Title: "yarrie",
+        HomePageURL: "http://yarrie.net/microblog",
+        FeedURL: "http://yarrie.net/microblog/feed.json",
+        Language: "en",
+        Authors: []JSONAuthor{{Name: "yarrie"}},
+        Items: []JSONItem{
+            {
+                ID: "http://yarrie.net/microblog#exampleid",
+                URL: "http://yarrie.net/microblog#exampleid",
+                ContentHTML: `<p>a <a href="x?a=1&b=2">link</a></p>`,
+                ContentText: "a link",
+                DatePublished: &published,
+                DateModified: &published,
+                Tags: []string{"music"},
+                Attachments: []JSONAttachment{{URL: "http://yarrie.net/a.mp3", MimeType: "audio/mpeg"}},
+            },
+            {
+                ID: "http://yarrie.net/microblog#empty",
+                ContentText: "",
+            },
+        },
+        Yarrienet: &JSONFeedExtension{
+            Deleted: []JSONDeletedItem{{ID: "http://yarrie.net/microblog#gone", DateDeleted: &published}},
+        },
+    }
+
+    data, err := EncodeJSONFeed(feed)
+    if err != nil {
+        t.Fatalf("failed to encode json feed: %s", err)
+    }
+    // html content is required even when empty
+    if strings.Count(string(data), `"content_html"`) != 2 {
+        t.Errorf("expected content_html on every item:\n%s", data)
+    }
+
+    decoded, err := DecodeJSONFeed(data)
+    if err != nil {
+        t.Fatalf("failed to decode encoded json feed: %s", err)
+    }
+    if !reflect.DeepEqual(decoded, feed) {
+        t.Errorf("round trip changed the feed\nexpected: %+v\ngot:      %+v", feed, decoded)
+    }
+}
@@ -5,23 +5,178 @@ import (
     "time"
 )
 
+// Namespace of the RSS content module, content:encoded.
+const ContentNamespace = "http://purl.org/rss/1.0/modules/content/"
+
+// Namespace of Dublin Core elements, e.g. dc:creator.
+const DublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+
+// Namespace of Media RSS, e.g. media:content.
+const MediaNamespace = "http://search.yahoo.com/mrss/"
+
+// Namespace of feed history (RFC 5005), e.g. fh:archive.
+const HistoryNamespace = "http://purl.org/syndication/history/1.0"
+
+// Marker of an archive document of a feed (RFC 5005), an empty fh:archive
+// element. Archive documents do not change once published.
+type HistoryArchive struct{}
+
+// Date layout of RSS 2.0 (RFC 822 with a four digit year).
+const rssDateLayout = "Mon, 02 Jan 2006 15:04:05 -0700"
+
 type RSS struct {
     XMLName xml.Name `xml:"rss"`
     Version string `xml:"version,attr"`
     Channel Channel `xml:"channel"`
     
 }
+
+// Channel of an RSS 2.0 feed with every element of the specification, see
+// https://www.rssboard.org/rss-specification. Optional elements are omitted
+// when empty. Dates are encoded by MarshalXML.
 type Channel struct {
+    // Links of the atom namespace, e.g. rel="self". Declared before Link so
+    // that decoding does not match atom:link elements to Link.
+    AtomLinks []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
     Title string `xml:"title"`
     Link string `xml:"link"`
     Description string `xml:"description"`
+    Language string `xml:"language,omitempty"`
+    Copyright string `xml:"copyright,omitempty"`
+    // Email address of the person responsible for editorial content.
+    ManagingEditor string `xml:"managingEditor,omitempty"`
+    // Email address of the person responsible for technical issues.
+    WebMaster string `xml:"webMaster,omitempty"`
+    PubDate time.Time `xml:"pubDate"`
+    // Last time the content of the channel changed.
+    LastBuildDate time.Time `xml:"lastBuildDate"`
+    Categories []Category `xml:"category"`
+    Generator string `xml:"generator,omitempty"`
+    // URL of the documentation of the format.
+    Docs string `xml:"docs,omitempty"`
+    Cloud *Cloud `xml:"cloud,omitempty"`
+    // Minutes the channel can be cached for, omitted when 0.
+    TTL int `xml:"ttl,omitempty"`
+    Image *Image `xml:"image,omitempty"`
+    Rating string `xml:"rating,omitempty"`
+    TextInput *TextInput `xml:"textInput,omitempty"`
+    SkipHours *SkipHours `xml:"skipHours,omitempty"`
+    SkipDays *SkipDays `xml:"skipDays,omitempty"`
+    // Whether the channel is an archive document, see HistoryArchive.
+    Archive *HistoryArchive `xml:"http://purl.org/syndication/history/1.0 archive,omitempty"`
     Items []Item `xml:"item"`
 }
+
+// Item of an RSS 2.0 channel with every element of the specification,
+// dc:creator, content:encoded and atom:updated. Optional elements are omitted when empty.
 type Item struct {
-    ID string `xml:"guid"`
-    Author string `xml:"author"`
+    Title string `xml:"title,omitempty"`
+    Link string `xml:"link,omitempty"`
+    Description string `xml:"description,omitempty"`
+    // Email address of the author, see Creator for a name.
+    Author string `xml:"author,omitempty"`
+    // Name of the author from Dublin Core.
+    Creator string `xml:"http://purl.org/dc/elements/1.1/ creator,omitempty"`
+    // Full HTML content from the content module, encoded as CDATA.
+    ContentEncoded *CDATA `xml:"http://purl.org/rss/1.0/modules/content/ encoded,omitempty"`
+    Categories []Category `xml:"category"`
+    // URL of the comments page.
+    Comments string `xml:"comments,omitempty"`
+    Enclosure *Enclosure `xml:"enclosure,omitempty"`
+    // Media objects from Media RSS, e.g. the images of the item.
+    MediaContents []MediaContent `xml:"http://search.yahoo.com/mrss/ content"`
+    GUID *GUID `xml:"guid,omitempty"`
+    PubDate time.Time `xml:"pubDate"`
+    // Last time the item changed from the atom namespace, optional.
+    Updated *time.Time `xml:"http://www.w3.org/2005/Atom updated,omitempty"`
+    Source *Source `xml:"source,omitempty"`
+}
+
+// Unique identifier of an item. The identifier is a permalink unless
+// IsPermaLink is false.
+type GUID struct {
+    // Whether the identifier is a URL of the item, true when nil.
+    IsPermaLink *bool `xml:"isPermaLink,attr,omitempty"`
+    Value string `xml:",chardata"`
+}
+
+// Category of a channel or item, optionally within a taxonomy domain.
+type Category struct {
+    Domain string `xml:"domain,attr,omitempty"`
+    Value string `xml:",chardata"`
+}
+
+// Media object attached to an item.
+type Enclosure struct {
+    URL string `xml:"url,attr"`
+    // Size in bytes.
+    Length int64 `xml:"length,attr"`
+    // MIME type.
+    Type string `xml:"type,attr"`
+}
+
+// Media object of an item from Media RSS, see
+// https://www.rssboard.org/media-rss. Optional attributes are omitted when
+// empty.
+type MediaContent struct {
+    URL string `xml:"url,attr"`
+    // Size in bytes.
+    FileSize int64 `xml:"fileSize,attr,omitempty"`
+    // MIME type.
+    Type string `xml:"type,attr,omitempty"`
+    // Kind of object, e.g. "image", "audio" or "video".
+    Medium string `xml:"medium,attr,omitempty"`
+    Width int `xml:"width,attr,omitempty"`
+    Height int `xml:"height,attr,omitempty"`
+    // Plain text description, e.g. the alternative text of an image.
+    Description string `xml:"http://search.yahoo.com/mrss/ description,omitempty"`
+}
+
+// Channel an item came from.
+type Source struct {
+    URL string `xml:"url,attr"`
+    Value string `xml:",chardata"`
+}
+
+// Image displayed with a channel.
+type Image struct {
+    URL string `xml:"url"`
+    Title string `xml:"title"`
     Link string `xml:"link"`
+    Width int `xml:"width,omitempty"`
+    Height int `xml:"height,omitempty"`
+    Description string `xml:"description,omitempty"`
+}
+
+// Cloud service notified of channel updates (rssCloud).
+type Cloud struct {
+    Domain string `xml:"domain,attr"`
+    Port int `xml:"port,attr"`
+    Path string `xml:"path,attr"`
+    RegisterProcedure string `xml:"registerProcedure,attr"`
+    Protocol string `xml:"protocol,attr"`
+}
+
+// Text input box displayed with a channel.
+type TextInput struct {
+    Title string `xml:"title"`
     Description string `xml:"description"`
-    PubDate time.Time `xml:"pubDate"`
+    Name string `xml:"name"`
+    Link string `xml:"link"`
+}
+
+// Hours (0-23 GMT) aggregators should skip reading the channel.
+type SkipHours struct {
+    Hours []int `xml:"hour"`
+}
+
+// Days (e.g. "Saturday") aggregators should skip reading the channel.
+type SkipDays struct {
+    Days []string `xml:"day"`
 }
 
+// Character data encoded as a CDATA section, e.g. HTML which is then not
+// escaped.
+type CDATA struct {
+    Text string `xml:",cdata"`
+}
@@ -0,0 +1,44 @@
+package rsshelper
+
+import (
+    "encoding/xml"
+)
+
+// OPML 2.0 outline, used as a list of feeds, see
+// http://opml.org/spec2.opml.
+type OPML struct {
+    XMLName xml.Name `xml:"opml"`
+    Version string `xml:"version,attr"`
+    Head OPMLHead `xml:"head"`
+    Body OPMLBody `xml:"body"`
+}
+
+type OPMLHead struct {
+    Title string `xml:"title"`
+}
+
+type OPMLBody struct {
+    Outlines []OPMLOutline `xml:"outline"`
+}
+
+// Outline of an OPML document subscribing to a feed. The type is "rss" for
+// feeds of any format.
+type OPMLOutline struct {
+    Type string `xml:"type,attr"`
+    Text string `xml:"text,attr"`
+    Title string `xml:"title,attr,omitempty"`
+    // URL of the feed.
+    XMLURL string `xml:"xmlUrl,attr"`
+    // URL of the page of the feed, optional.
+    HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+    Description string `xml:"description,attr,omitempty"`
+}
+
+// Encode the OPML document as indented XML with an XML declaration.
+func EncodeOPML(opml *OPML) ([]byte, error) {
+    data, err := xml.MarshalIndent(opml, "", "    ")
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,691 @@
+package rsshelper
+
+import (
+    "bytes"
+    "encoding/json"
+    "encoding/xml"
+    "fmt"
+    "net/mail"
+    "net/url"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Severities of validation problems.
+const (
+    SeverityError = "error"
+    SeverityWarning = "warning"
+)
+
+// Problem found by Validate in a feed.
+type Problem struct {
+    // SeverityError when the feed breaks its specification, SeverityWarning
+    // when it breaks a recommendation or is likely to confuse readers.
+    Severity string `json:"severity"`
+    // Path of the element, e.g. "rss/channel/item[2]/guid" or
+    // "items[2].id" in JSON Feed. Repeated elements are numbered from 1.
+    Path string `json:"path"`
+    Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+    return fmt.Sprintf("%s %s: %s", p.Severity, p.Path, p.Message)
+}
+
+// Whether any of the problems is an error.
+func HasErrors(problems []Problem) bool {
+    for _, p := range problems {
+        if p.Severity == SeverityError {
+            return true
+        }
+    }
+    return false
+}
+
+// Problems collected while validating.
+type validator struct {
+    problems []Problem
+}
+
+func (v *validator) errorf(path string, format string, args ...any) {
+    v.problems = append(v.problems, Problem{SeverityError, path, fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(path string, format string, args ...any) {
+    v.problems = append(v.problems, Problem{SeverityWarning, path, fmt.Sprintf(format, args...)})
+}
+
+// Validate a feed against the rules of its dialect: RSS 2.0
+// (https://www.rssboard.org/rss-specification), Atom 1.0 (RFC 4287) or JSON
+// Feed 1.1. RSS 1.0 is only checked to be decodable. Returns an error when
+// the feed cannot be read at all.
+func Validate(data []byte) ([]Problem, error) {
+    trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), " \t\r\n")
+    v := &validator{}
+    if len(trimmed) > 0 && trimmed[0] == '{' {
+        if err := v.validateJSONFeed(trimmed); err != nil {
+            return nil, err
+        }
+        return v.problems, nil
+    }
+
+    root, err := parseXMLTree(data)
+    if err != nil {
+        return nil, err
+    }
+    switch {
+    case root.Name.Local == "rss":
+        v.validateRSS(root)
+    case root.Name.Local == "feed" && root.Name.Space == AtomNamespace:
+        v.validateAtom(root)
+    case root.Name.Local == "RDF" && root.Name.Space == RDFNamespace:
+        if _, err := decodeRDF(data); err != nil {
+            v.errorf("rdf:RDF", "%s", err)
+        }
+    default:
+        return nil, fmt.Errorf("unknown feed format with root element <%s>", root.Name.Local)
+    }
+    return v.problems, nil
+}
+
+// Element of an XML document with its text, used to validate the raw
+// values and positions of elements.
+type xmlNode struct {
+    Name xml.Name
+    Attr []xml.Attr
+    // Character data directly within the element, trimmed.
+    Text string
+    Children []*xmlNode
+    // Path of the element, see Problem.
+    Path string
+}
+
+// Prefixes of namespaces in paths.
+var namespacePrefixes = map[string]string{
+    AtomNamespace: "atom",
+    ContentNamespace: "content",
+    DublinCoreNamespace: "dc",
+    MediaNamespace: "media",
+    TombstonesNamespace: "at",
+    RDFNamespace: "rdf",
+    HistoryNamespace: "fh",
+}
+
+// Name of an element in a path, prefixed when in a namespace other than the
+// one of its parent.
+func pathName(name xml.Name, parentSpace string) string {
+    if name.Space == "" || name.Space == parentSpace {
+        return name.Local
+    }
+    if prefix, ok := namespacePrefixes[name.Space]; ok {
+        return prefix + ":" + name.Local
+    }
+    return name.Local
+}
+
+// Parse an XML document into a tree of elements.
+func parseXMLTree(data []byte) (*xmlNode, error) {
+    d := xml.NewDecoder(bytes.NewReader(data))
+    d.Strict = false
+    d.Entity = xml.HTMLEntity
+    d.CharsetReader = charsetReader
+    var root *xmlNode
+    var stack []*xmlNode
+    // counts of each child name by parent, to number repeated elements
+    counts := map[*xmlNode]map[string]int{}
+    var text []*strings.Builder
+    for {
+        token, err := d.Token()
+        if err != nil {
+            if root != nil && len(stack) == 0 {
+                return root, nil
+            }
+            return nil, fmt.Errorf("malformed xml: %w", err)
+        }
+        switch t := token.(type) {
+        case xml.StartElement:
+            node := &xmlNode{Name: t.Name, Attr: t.Attr}
+            if len(stack) == 0 {
+                if root != nil {
+                    return nil, fmt.Errorf("malformed xml: more than one root element")
+                }
+                root = node
+                node.Path = t.Name.Local
+            } else {
+                parent := stack[len(stack)-1]
+                parent.Children = append(parent.Children, node)
+                name := pathName(t.Name, parent.Name.Space)
+                if counts[parent] == nil {
+                    counts[parent] = map[string]int{}
+                }
+                counts[parent][name]++
+                node.Path = fmt.Sprintf("%s/%s[%d]", parent.Path, name, counts[parent][name])
+            }
+            stack = append(stack, node)
+            text = append(text, &strings.Builder{})
+        case xml.CharData:
+            if len(text) > 0 {
+                text[len(text)-1].Write(t)
+            }
+        case xml.EndElement:
+            if len(stack) == 0 {
+                return nil, fmt.Errorf("malformed xml: unexpected </%s>", t.Name.Local)
+            }
+            node := stack[len(stack)-1]
+            node.Text = strings.TrimSpace(text[len(text)-1].String())
+            stack = stack[:len(stack)-1]
+            text = text[:len(text)-1]
+        }
+    }
+}
+
+// Remove the index of elements which are not repeated, e.g.
+// "rss[1]/channel[1]/title[1]" becomes "rss/channel/title" while
+// "item[2]" is kept.
+func simplifyPaths(node *xmlNode) {
+    counts := map[string]int{}
+    for _, child := range node.Children {
+        counts[pathName(child.Name, node.Name.Space)]++
+    }
+    for _, child := range node.Children {
+        name := pathName(child.Name, node.Name.Space)
+        if counts[name] == 1 {
+            child.Path = node.Path + "/" + name
+        } else {
+            child.Path = node.Path + child.Path[strings.LastIndex(child.Path, "/"):]
+        }
+        simplifyPaths(child)
+    }
+}
+
+// Children of the element with the name in the namespace, "" for no
+// namespace or the namespace of the parent.
+func (n *xmlNode) all(space string, local string) []*xmlNode {
+    var found []*xmlNode
+    for _, child := range n.Children {
+        childSpace := child.Name.Space
+        if childSpace == n.Name.Space {
+            childSpace = ""
+        }
+        if child.Name.Local == local && childSpace == space {
+            found = append(found, child)
+        }
+    }
+    return found
+}
+
+// First child of the element with the name, nil when missing.
+func (n *xmlNode) first(space string, local string) *xmlNode {
+    if found := n.all(space, local); len(found) > 0 {
+        return found[0]
+    }
+    return nil
+}
+
+// Value of an attribute without a namespace, and whether it is present.
+func (n *xmlNode) attr(local string) (string, bool) {
+    for _, a := range n.Attr {
+        if a.Name.Local == local && a.Name.Space == "" {
+            return a.Value, true
+        }
+    }
+    return "", false
+}
+
+// Whether the string is an absolute URL with a scheme and, for http(s), a
+// host.
+func isAbsoluteURL(s string) bool {
+    u, err := url.Parse(strings.TrimSpace(s))
+    if err != nil || u.Scheme == "" {
+        return false
+    }
+    if u.Scheme == "http" || u.Scheme == "https" {
+        return u.Host != ""
+    }
+    return true
+}
+
+// Check that a URL value is absolute.
+func (v *validator) checkURL(path string, s string) {
+    if s == "" {
+        v.errorf(path, "empty url")
+    } else if !isAbsoluteURL(s) {
+        v.errorf(path, "'%s' is not an absolute url", s)
+    }
+}
+
+// Check that a required child element is present and not empty. Returns
+// the element, nil when missing.
+func (v *validator) required(n *xmlNode, space string, local string) *xmlNode {
+    child := n.first(space, local)
+    if child == nil {
+        v.errorf(n.Path, "missing required <%s>", pathName(xml.Name{Space: space, Local: local}, ""))
+    } else if child.Text == "" && len(child.Children) == 0 {
+        v.errorf(child.Path, "empty <%s>", local)
+    }
+    return child
+}
+
+// Layouts of RFC 822 dates as required by RSS 2.0, with the four digit years
+// RSS recommends.
+var strictRFC822Layouts = []string{
+    time.RFC1123Z,
+    time.RFC1123,
+    "Mon, 02 Jan 2006 15:04 -0700",
+    "Mon, 02 Jan 2006 15:04 MST",
+    "Mon, 2 Jan 2006 15:04:05 -0700",
+    "Mon, 2 Jan 2006 15:04:05 MST",
+    "2 Jan 2006 15:04:05 -0700",
+    "2 Jan 2006 15:04:05 MST",
+}
+
+// Check that a parsed date is not in year 1, the zero time of Go written by
+// generators in place of a missing date.
+func (v *validator) checkZeroDate(path string, s string, t time.Time) {
+    if t.Year() <= 1 {
+        v.errorf(path, "'%s' is the zero date, most likely a missing date", s)
+    }
+}
+
+// Check that an element is an RFC 822 date. Dates read by ParseDate in
+// other forms are warnings as not every reader accepts them.
+func (v *validator) checkRFC822Date(n *xmlNode) {
+    for _, layout := range strictRFC822Layouts {
+        if t, err := time.Parse(layout, n.Text); err == nil {
+            v.checkZeroDate(n.Path, n.Text, t)
+            return
+        }
+    }
+    if t, err := ParseDate(n.Text); err == nil {
+        v.warnf(n.Path, "'%s' is not an RFC 822 date", n.Text)
+        v.checkZeroDate(n.Path, n.Text, t)
+    } else {
+        v.errorf(n.Path, "invalid date '%s'", n.Text)
+    }
+}
+
+// Check that an element or attribute value is an RFC3339 date.
+func (v *validator) checkRFC3339Date(path string, s string) {
+    t, err := time.Parse(time.RFC3339, s)
+    if err != nil {
+        v.errorf(path, "'%s' is not an RFC3339 date", s)
+        return
+    }
+    v.checkZeroDate(path, s, t)
+}
+
+// Check that an element is an email address, optionally followed by a name
+// in parentheses as RSS 2.0 requires, e.g. "editor@yarrie.net (yarrie)".
+func (v *validator) checkRSSEmail(n *xmlNode) {
+    address := n.Text
+    if open := strings.Index(address, "("); open != -1 && strings.HasSuffix(address, ")") {
+        address = strings.TrimSpace(address[:open])
+    }
+    if _, err := mail.ParseAddress(address); err != nil || !strings.Contains(address, "@") {
+        v.errorf(n.Path, "'%s' is not an email address, names belong in dc:creator", n.Text)
+    }
+}
+
+// Check that an element is a non-negative integer.
+func (v *validator) checkInteger(path string, s string) (int, bool) {
+    i, err := strconv.Atoi(strings.TrimSpace(s))
+    if err != nil || i < 0 {
+        v.errorf(path, "'%s' is not a non-negative integer", s)
+        return 0, false
+    }
+    return i, true
+}
+
+// Language tags of the form "en" or "en-GB".
+var languagePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+// Days of skipDays.
+var weekdays = map[string]bool{
+    "Monday": true, "Tuesday": true, "Wednesday": true, "Thursday": true,
+    "Friday": true, "Saturday": true, "Sunday": true,
+}
+
+// Validate an RSS 2.0 document.
+func (v *validator) validateRSS(root *xmlNode) {
+    simplifyPaths(root)
+    if version, _ := root.attr("version"); version != "2.0" {
+        v.warnf(root.Path, "version '%s' is not 2.0, only RSS 2.0 rules are checked", version)
+    }
+    channel := root.first("", "channel")
+    if channel == nil {
+        v.errorf(root.Path, "missing required <channel>")
+        return
+    }
+    v.required(channel, "", "title")
+    if link := v.required(channel, "", "link"); link != nil && link.Text != "" {
+        v.checkURL(link.Path, link.Text)
+    }
+    if channel.first("", "description") == nil {
+        v.errorf(channel.Path, "missing required <description>")
+    }
+    if language := channel.first("", "language"); language != nil && !languagePattern.MatchString(language.Text) {
+        v.warnf(language.Path, "'%s' is not a language tag", language.Text)
+    }
+    for _, name := range []string{"pubDate", "lastBuildDate"} {
+        if date := channel.first("", name); date != nil {
+            v.checkRFC822Date(date)
+        }
+    }
+    for _, name := range []string{"managingEditor", "webMaster"} {
+        if email := channel.first("", name); email != nil {
+            v.checkRSSEmail(email)
+        }
+    }
+    for _, name := range []string{"docs"} {
+        if u := channel.first("", name); u != nil {
+            v.checkURL(u.Path, u.Text)
+        }
+    }
+    if ttl := channel.first("", "ttl"); ttl != nil {
+        v.checkInteger(ttl.Path, ttl.Text)
+    }
+    if image := channel.first("", "image"); image != nil {
+        if u := v.required(image, "", "url"); u != nil && u.Text != "" {
+            v.checkURL(u.Path, u.Text)
+        }
+        v.required(image, "", "title")
+        v.required(image, "", "link")
+        if width := image.first("", "width"); width != nil {
+            if w, ok := v.checkInteger(width.Path, width.Text); ok && w > 144 {
+                v.errorf(width.Path, "image width %d is above the maximum of 144", w)
+            }
+        }
+        if height := image.first("", "height"); height != nil {
+            if h, ok := v.checkInteger(height.Path, height.Text); ok && h > 400 {
+                v.errorf(height.Path, "image height %d is above the maximum of 400", h)
+            }
+        }
+    }
+    if skipHours := channel.first("", "skipHours"); skipHours != nil {
+        for _, hour := range skipHours.all("", "hour") {
+            if h, ok := v.checkInteger(hour.Path, hour.Text); ok && h > 23 {
+                v.errorf(hour.Path, "hour %d is not 0-23", h)
+            }
+        }
+    }
+    if skipDays := channel.first("", "skipDays"); skipDays != nil {
+        for _, day := range skipDays.all("", "day") {
+            if !weekdays[day.Text] {
+                v.errorf(day.Path, "'%s' is not a day, e.g. Saturday", day.Text)
+            }
+        }
+    }
+
+    var self bool
+    for _, link := range channel.all(AtomNamespace, "link") {
+        href, _ := link.attr("href")
+        v.checkURL(link.Path, href)
+        if rel, _ := link.attr("rel"); rel == "self" {
+            self = true
+        }
+    }
+    if !self {
+        v.warnf(channel.Path, "missing <atom:link rel=\"self\"> giving the url of the feed")
+    }
+
+    guids := map[string]string{}
+    for _, item := range channel.all("", "item") {
+        v.validateRSSItem(item, guids)
+    }
+}
+
+// Validate an item of an RSS 2.0 channel. Guids are the paths of the guids
+// seen so far by value.
+func (v *validator) validateRSSItem(item *xmlNode, guids map[string]string) {
+    title, description := item.first("", "title"), item.first("", "description")
+    if title == nil && description == nil {
+        v.errorf(item.Path, "an item requires a <title> or <description>")
+    }
+    if link := item.first("", "link"); link != nil {
+        v.checkURL(link.Path, link.Text)
+    }
+    if comments := item.first("", "comments"); comments != nil {
+        v.checkURL(comments.Path, comments.Text)
+    }
+    if author := item.first("", "author"); author != nil {
+        v.checkRSSEmail(author)
+    }
+    if pubDate := item.first("", "pubDate"); pubDate != nil {
+        v.checkRFC822Date(pubDate)
+    } else {
+        v.warnf(item.Path, "missing <pubDate>, readers will date the item when first seen")
+    }
+
+    guid := item.first("", "guid")
+    if guid == nil {
+        v.warnf(item.Path, "missing <guid>, readers identify the item by its content")
+    } else if guid.Text == "" {
+        v.errorf(guid.Path, "empty <guid>")
+    } else {
+        isPermaLink, _ := guid.attr("isPermaLink")
+        if isPermaLink != "false" && !isAbsoluteURL(guid.Text) {
+            v.errorf(guid.Path, "'%s' is not a url, add isPermaLink=\"false\"", guid.Text)
+        }
+        if previous, ok := guids[guid.Text]; ok {
+            v.errorf(guid.Path, "duplicate guid '%s' of %s", guid.Text, previous)
+        } else {
+            guids[guid.Text] = guid.Path
+        }
+    }
+
+    for _, enclosure := range item.all("", "enclosure") {
+        u, _ := enclosure.attr("url")
+        v.checkURL(enclosure.Path, u)
+        if length, ok := enclosure.attr("length"); !ok {
+            v.errorf(enclosure.Path, "missing required length attribute")
+        } else {
+            v.checkInteger(enclosure.Path, length)
+        }
+        if t, _ := enclosure.attr("type"); t == "" {
+            v.errorf(enclosure.Path, "missing required type attribute")
+        }
+    }
+    if len(item.all("", "enclosure")) > 1 {
+        v.warnf(item.Path, "more than one <enclosure>, most readers only use the first")
+    }
+    if source := item.first("", "source"); source != nil {
+        u, _ := source.attr("url")
+        v.checkURL(source.Path, u)
+    }
+}
+
+// Validate an Atom 1.0 document.
+func (v *validator) validateAtom(root *xmlNode) {
+    simplifyPaths(root)
+    if id := v.required(root, "", "id"); id != nil && id.Text != "" && !isAbsoluteURL(id.Text) {
+        v.errorf(id.Path, "'%s' is not an absolute iri", id.Text)
+    }
+    v.required(root, "", "title")
+    if updated := v.required(root, "", "updated"); updated != nil && updated.Text != "" {
+        v.checkRFC3339Date(updated.Path, updated.Text)
+    }
+    feedAuthor := root.first("", "author") != nil
+    v.checkAtomLinks(root)
+    var self bool
+    for _, link := range root.all("", "link") {
+        if rel, _ := link.attr("rel"); rel == "self" {
+            self = true
+        }
+    }
+    if !self {
+        v.warnf(root.Path, "missing <link rel=\"self\"> giving the url of the feed")
+    }
+
+    ids := map[string]string{}
+    for _, entry := range root.all("", "entry") {
+        id := v.required(entry, "", "id")
+        if id != nil && id.Text != "" {
+            if !isAbsoluteURL(id.Text) {
+                v.errorf(id.Path, "'%s' is not an absolute iri", id.Text)
+            }
+            if previous, ok := ids[id.Text]; ok {
+                v.errorf(id.Path, "duplicate id '%s' of %s", id.Text, previous)
+            } else {
+                ids[id.Text] = id.Path
+            }
+        }
+        v.required(entry, "", "title")
+        if updated := v.required(entry, "", "updated"); updated != nil && updated.Text != "" {
+            v.checkRFC3339Date(updated.Path, updated.Text)
+        }
+        if published := entry.first("", "published"); published != nil {
+            v.checkRFC3339Date(published.Path, published.Text)
+        }
+        if !feedAuthor && entry.first("", "author") == nil {
+            v.errorf(entry.Path, "missing <author> required when the feed has none")
+        }
+        v.checkAtomLinks(entry)
+        var alternate bool
+        for _, link := range entry.all("", "link") {
+            if rel, _ := link.attr("rel"); rel == "" || rel == "alternate" {
+                alternate = true
+            }
+        }
+        content := entry.first("", "content")
+        if content == nil && !alternate {
+            v.errorf(entry.Path, "an entry requires <content> or <link rel=\"alternate\">")
+        }
+        if content != nil {
+            if t, _ := content.attr("type"); t != "" && t != "text" && t != "html" && t != "xhtml" && !strings.Contains(t, "/") {
+                v.errorf(content.Path, "unknown content type '%s'", t)
+            }
+        }
+    }
+    for _, deleted := range root.all(TombstonesNamespace, "deleted-entry") {
+        if ref, _ := deleted.attr("ref"); ref == "" {
+            v.errorf(deleted.Path, "missing required ref attribute")
+        }
+        if when, ok := deleted.attr("when"); !ok {
+            v.errorf(deleted.Path, "missing required when attribute")
+        } else {
+            v.checkRFC3339Date(deleted.Path, when)
+        }
+    }
+}
+
+// Check the links of an Atom feed or entry have an absolute href.
+func (v *validator) checkAtomLinks(n *xmlNode) {
+    for _, link := range n.all("", "link") {
+        href, ok := link.attr("href")
+        if !ok {
+            v.errorf(link.Path, "missing required href attribute")
+        } else if !isAbsoluteURL(href) {
+            v.warnf(link.Path, "'%s' is not an absolute url", href)
+        }
+    }
+}
+
+// Validate a JSON Feed.
+func (v *validator) validateJSONFeed(data []byte) error {
+    var feed validatedJSONFeed
+    if err := json.Unmarshal(data, &feed); err != nil {
+        return fmt.Errorf("malformed json feed: %w", err)
+    }
+    if feed.Version != JSONFeedVersion && feed.Version != "https://jsonfeed.org/version/1" {
+        v.errorf("version", "unknown version '%s'", feed.Version)
+    }
+    if feed.Title == "" {
+        v.errorf("title", "missing required title")
+    }
+    if feed.HomePageURL != "" && !isAbsoluteURL(feed.HomePageURL) {
+        v.errorf("home_page_url", "'%s' is not an absolute url", feed.HomePageURL)
+    }
+    if feed.FeedURL == "" {
+        v.warnf("feed_url", "missing feed_url giving the url of the feed")
+    } else if !isAbsoluteURL(feed.FeedURL) {
+        v.errorf("feed_url", "'%s' is not an absolute url", feed.FeedURL)
+    }
+    if feed.Language != "" && !languagePattern.MatchString(feed.Language) {
+        v.warnf("language", "'%s' is not a language tag", feed.Language)
+    }
+    v.checkJSONAuthors("authors", feed.Authors)
+
+    ids := map[string]string{}
+    for i, item := range feed.Items {
+        path := fmt.Sprintf("items[%d]", i + 1)
+        if item.ID == "" {
+            v.errorf(path + ".id", "missing required id")
+        } else if previous, ok := ids[item.ID]; ok {
+            v.errorf(path + ".id", "duplicate id '%s' of %s", item.ID, previous)
+        } else {
+            ids[item.ID] = path + ".id"
+        }
+        if item.ContentHTML == "" && item.ContentText == "" {
+            v.errorf(path, "an item requires content_html or content_text")
+        }
+        if item.URL != "" && !isAbsoluteURL(item.URL) {
+            v.errorf(path + ".url", "'%s' is not an absolute url", item.URL)
+        }
+        if item.DatePublished == nil {
+            v.warnf(path, "missing date_published")
+        } else {
+            v.checkRFC3339Date(path + ".date_published", *item.DatePublished)
+        }
+        if item.DateModified != nil {
+            v.checkRFC3339Date(path + ".date_modified", *item.DateModified)
+        }
+        v.checkJSONAuthors(path + ".authors", item.Authors)
+        for j, attachment := range item.Attachments {
+            attachmentPath := fmt.Sprintf("%s.attachments[%d]", path, j + 1)
+            if !isAbsoluteURL(attachment.URL) {
+                v.errorf(attachmentPath + ".url", "'%s' is not an absolute url", attachment.URL)
+            }
+            if attachment.MimeType == "" {
+                v.errorf(attachmentPath + ".mime_type", "missing required mime_type")
+            }
+        }
+    }
+    if feed.Yarrienet != nil {
+        for i, deleted := range feed.Yarrienet.Deleted {
+            path := fmt.Sprintf("_yarrienet.deleted[%d]", i + 1)
+            if deleted.ID == "" {
+                v.errorf(path + ".id", "missing required id")
+            }
+            if deleted.DateDeleted != nil {
+                v.checkRFC3339Date(path + ".date_deleted", *deleted.DateDeleted)
+            }
+        }
+    }
+    return nil
+}
+
+// JSON Feed decoded for validation with its dates kept as strings, so that an
+// invalid date is reported at its path rather than failing to decode the
+// feed. The fields shadow those of the embedded types.
+type validatedJSONFeed struct {
+    JSONFeed
+    Items []validatedJSONItem `json:"items"`
+    Yarrienet *struct {
+        Deleted []struct {
+            ID string `json:"id"`
+            DateDeleted *string `json:"date_deleted"`
+        } `json:"deleted"`
+    } `json:"_yarrienet"`
+}
+
+// JSON Feed item with its dates as strings, see validatedJSONFeed.
+type validatedJSONItem struct {
+    jsonItemFields
+    DatePublished *string `json:"date_published"`
+    DateModified *string `json:"date_modified"`
+}
+
+// Fields of a JSON Feed item without its date decoding, so that the dates of
+// validatedJSONItem are read as they are.
+type jsonItemFields JSONItem
+
+// Check that each JSON Feed author has a name, url or avatar.
+func (v *validator) checkJSONAuthors(path string, authors []JSONAuthor) {
+    for i, author := range authors {
+        if author.Name == "" && author.URL == "" && author.Avatar == "" {
+            v.errorf(fmt.Sprintf("%s[%d]", path, i + 1), "an author requires a name, url or avatar")
+        }
+    }
+}
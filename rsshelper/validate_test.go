@@ -0,0 +1,94 @@
+package rsshelper
+
+import (
+    "os"
+    "path/filepath"
+    "reflect"
+    "testing"
+)
+
+// Testing that the valid fixtures of each dialect have no errors.
+func TestValidateFixtures(t *testing.T) {
+    for _, name := range []string{"rss20.xml", "rdf.xml", "atom.xml", "jsonfeed10.json", "jsonfeed11.json"} {
+        data, err := os.ReadFile(filepath.Join("testdata", name))
+        if err != nil {
+            t.Fatalf("failed to read fixture: %s", err)
+        }
+        problems, err := Validate(data)
+        if err != nil {
+            t.Errorf("failed to validate %s: %s", name, err)
+        } else if len(problems) > 0 {
+            t.Errorf("expected %s to be valid, got %v", name, problems)
+        }
+    }
+}
+
+// Testing the problems found in the invalid fixtures, with their paths.
+func TestValidateInvalid(t *testing.T) {
+    tests := map[string][]Problem{
+        "invalid-rss.xml": {
+            {SeverityError, "rss/channel/link", "'/microblog' is not an absolute url"},
+            {SeverityError, "rss/channel", "missing required <description>"},
+            {SeverityWarning, "rss/channel/lastBuildDate", "'2025-04-15T08:00:00Z' is not an RFC 822 date"},
+            {SeverityError, "rss/channel/managingEditor", "'yarrie' is not an email address, names belong in dc:creator"},
+            {SeverityError, "rss/channel/item[1]/link", "'microblog#second' is not an absolute url"},
+            {SeverityError, "rss/channel/item[1]/author", "'yarrie' is not an email address, names belong in dc:creator"},
+            {SeverityError, "rss/channel/item[1]/guid", "'second' is not a url, add isPermaLink=\"false\""},
+            {SeverityError, "rss/channel/item[2]/pubDate", "invalid date 'yesterday'"},
+            {SeverityError, "rss/channel/item[2]/guid", "duplicate guid 'second' of rss/channel/item[1]/guid"},
+            {SeverityError, "rss/channel/item[2]/enclosure", "missing required length attribute"},
+            {SeverityWarning, "rss/channel/item[3]", "missing <pubDate>, readers will date the item when first seen"},
+            {SeverityWarning, "rss/channel/item[3]", "missing <guid>, readers identify the item by its content"},
+        },
+        "invalid-atom.xml": {
+            {SeverityError, "feed/updated", "'Tue, 15 Apr 2025 08:00:00 GMT' is not an RFC3339 date"},
+            {SeverityWarning, "feed", "missing <link rel=\"self\"> giving the url of the feed"},
+            {SeverityWarning, "feed/entry[1]/link", "'microblog#second' is not an absolute url"},
+            {SeverityError, "feed/entry[2]/id", "duplicate id 'http://yarrie.net/microblog#second' of feed/entry[1]/id"},
+            {SeverityError, "feed/entry[2]", "missing required <title>"},
+            {SeverityError, "feed/entry[2]", "an entry requires <content> or <link rel=\"alternate\">"},
+            {SeverityError, "feed/entry[3]/updated", "'0001-01-01T00:00:00Z' is the zero date, most likely a missing date"},
+            {SeverityError, "feed/entry[3]", "missing <author> required when the feed has none"},
+        },
+        "invalid-jsonfeed.json": {
+            {SeverityError, "title", "missing required title"},
+            {SeverityWarning, "feed_url", "missing feed_url giving the url of the feed"},
+            {SeverityError, "authors[1]", "an author requires a name, url or avatar"},
+            {SeverityError, "items[1].url", "'microblog#second' is not an absolute url"},
+            {SeverityError, "items[1].attachments[1].url", "'/audio/song.mp3' is not an absolute url"},
+            {SeverityError, "items[1].attachments[1].mime_type", "missing required mime_type"},
+            {SeverityError, "items[2].id", "duplicate id 'http://yarrie.net/microblog#second' of items[1].id"},
+            {SeverityError, "items[2]", "an item requires content_html or content_text"},
+            {SeverityError, "items[2].date_published", "'yesterday' is not an RFC3339 date"},
+            {SeverityError, "items[3].date_published", "'0001-01-01T00:00:00Z' is the zero date, most likely a missing date"},
+            {SeverityError, "items[3].date_modified", "'2025-04-11' is not an RFC3339 date"},
+            {SeverityError, "_yarrienet.deleted[1].date_deleted", "'15 April' is not an RFC3339 date"},
+        },
+    }
+    for name, expected := range tests {
+        data, err := os.ReadFile(filepath.Join("testdata", name))
+        if err != nil {
+            t.Fatalf("failed to read fixture: %s", err)
+        }
+        problems, err := Validate(data)
+        if err != nil {
+            t.Errorf("failed to validate %s: %s", name, err)
+            continue
+        }
+        if !reflect.DeepEqual(problems, expected) {
+            t.Errorf("unexpected problems in %s\nexpected: %v\ngot:      %v", name, expected, problems)
+        }
+        if !HasErrors(problems) {
+            t.Errorf("expected %s to have errors", name)
+        }
+    }
+}
+
+// Testing that documents which are not feeds cannot be validated.
+func TestValidateUnknown(t *testing.T) {
+    for _, data := range []string{`<html><body></body></html>`, `{"version": `, ``, `<rss><channel>`} {
+        if _, err := Validate([]byte(data)); err == nil {
+            t.Errorf("expected validating '%s' to fail", data)
+        }
+    }
+}
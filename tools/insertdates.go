@@ -76,14 +76,16 @@ func insertDateNodes(doc *html.Node, dates map[string]time.Time) *html.Node {
 }
 
 func determineRssDates(data []byte) (map[string]time.Time, error) {
-    items, err := rsshelper.Decode(data)
+    feed, err := rsshelper.Decode(data)
     if err != nil {
         return nil, err
     }
 
-    postDates := make(map[string]time.Time, len(items))
-    for _, item := range items {
-        postDates[item.ID] = item.PubDate
+    postDates := make(map[string]time.Time, len(feed.Items))
+    for _, item := range feed.Items {
+        if item.ID != "" {
+            postDates[item.ID] = item.Published
+        }
     }
     return postDates, nil
 }